@@ -22,3 +22,11 @@ var MaxLenRec = 600
 // MaxLenRecFC is the maximum slice length for recursion when
 // sorting strings or calling [Sort]().
 var MaxLenRecFC = 300
+
+// MinLenRadix is the minimum integer/float slice length for radix sort when a scratch
+// space is available, see [SortSliceRadix].
+var MinLenRadix = 1000
+
+// MinLenCount is the minimum ratio of integer slice length to its range of values
+// for counting sort, which also bounds its counter memory. It must be at least 1.
+var MinLenCount = 8
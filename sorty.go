@@ -11,14 +11,24 @@
 //	import "github.com/jfcg/sorty/v2"
 //
 //	sorty.SortSlice(native_slice) // []int, []float64, []string, []*T etc. in ascending order
+//	sorty.SortOrdered(slice)      // type-checked generic version for any ordered slice type
 //	sorty.SortLen(len_slice)      // []string or [][]T 'by length' in ascending order
+//	sorty.SortByKey(slice, key)   // by key() of members, with native kernels
 //	sorty.Sort(n, lesswap)        // lesswap() based
+//	sorty.SortFunc(slice, cmp)    // cmp() based, like slices.SortFunc
 //
 // [QuickSort]: https://en.wikipedia.org/wiki/Quicksort
 package sorty
 
 import (
+	"cmp"
+	"context"
+	"fmt"
+	"math/bits"
 	"reflect"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
 	"unsafe"
 
 	"github.com/jfcg/sixb/v2"
@@ -30,6 +40,42 @@ import (
 // sorting: sorty will not create any goroutines or channel.
 var MaxGor uint64 = 3
 
+var (
+	globalMax uint64 // process-wide goroutine budget, 0 for none
+	globalGor uint64 // number of goroutines spawned by all sorting calls
+)
+
+// SetGlobalMaxGor sets a process-wide budget for the number of goroutines that all
+// ongoing sorting calls together can spawn, in addition to per-call [MaxGor]. A call
+// continues in its own goroutine(s) when the budget is exhausted. n = 0 removes the
+// budget, which is the default. It can be called live, even during ongoing calls;
+// calls started while there is no budget are not counted against a later one.
+func SetGlobalMaxGor(n uint64) {
+	atomic.StoreUint64(&globalMax, n)
+}
+
+// Executor runs concurrent work of sorting calls, for example on a user's own
+// scheduler with tracing & priorities. Go must run fn in a goroutine other than its
+// caller's. Sorting calls wait for their concurrent work, so an executor with bounded
+// workers must not delay fn indefinitely behind sorting calls running on its workers.
+// A [Pool] is also an Executor.
+type Executor interface {
+	Go(fn func())
+}
+
+var executor atomic.Pointer[Executor] // nil for new goroutines
+
+// SetExecutor makes sorty submit all concurrent work (that is not handled by a
+// [Pool]) to ex instead of starting new goroutines. nil restores the default
+// behaviour. It can be called live, even during ongoing calls.
+func SetExecutor(ex Executor) {
+	if ex == nil {
+		executor.Store(nil)
+	} else {
+		executor.Store(&ex)
+	}
+}
+
 func init() {
 	if !(4097 > MaxGor && MaxGor > 0 && MaxLenRec > MaxLenRecFC && MaxLenRecFC >
 		2*MaxLenIns && MaxLenIns > MaxLenInsFC && MaxLenInsFC > 2*nsShort) {
@@ -81,14 +127,401 @@ func Search(n int, fn func(int) bool) int {
 type syncVar struct {
 	nGor uint64   // number of sorting goroutines
 	done chan int // end signal
+	mg   *uint64  // max goroutines
+	stop *uint32  // cancellation flags, can be nil
+	pnc  *Panic   // first panic in a spawned goroutine
+	wp   *Pool    // worker pool, can be nil
+	own  uint32   // cancellation flags when caller has none
+	glob bool     // counted in process-wide goroutine budget
+}
+
+// newSyncVar returns synchronization variables for concurrent sorting, reused from
+// wp if it is not nil
+func newSyncVar(mg *uint64, stop *uint32, wp *Pool) (sv *syncVar) {
+	if wp != nil {
+		sv = wp.vars.Get().(*syncVar)
+	} else {
+		sv = &syncVar{done: make(chan int)} // end signal
+	}
+	sv.nGor = 1 // number of goroutines including this
+	sv.mg, sv.stop, sv.pnc, sv.wp = mg, stop, nil, wp
+	sv.glob = atomic.LoadUint64(&globalMax) != 0
+	return
+}
+
+// ownStop makes sv use its own cancellation flags if it has none, to stop all
+// goroutines when a user function panics
+func ownStop(sv *syncVar) {
+	if sv.stop == nil {
+		sv.own = 0
+		sv.stop = &sv.own
+	}
+}
+
+// release returns sv to its pool after all goroutines are done
+func release(sv *syncVar) {
+	if wp := sv.wp; wp != nil {
+		wp.vars.Put(sv)
+	}
+}
+
+// submit runs fn in an idle worker of wp if any, otherwise via [Executor] if it is
+// set, otherwise in a new goroutine
+func submit(wp *Pool, fn func()) {
+	if wp != nil && wp.run(fn) {
+		return
+	}
+	if ex := executor.Load(); ex != nil {
+		(*ex).Go(fn)
+		return
+	}
+	go fn()
+}
+
+// taskList keeps idle tasks for reuse
+type taskList struct {
+	mu   sync.Mutex
+	free []any // of *task[P, Q]
+}
+
+var exTasks taskList // idle tasks for Executor when there is no pool
+
+// task keeps a function & its arguments for a worker, so spawning via a pool or
+// executor does not allocate once enough tasks of its type are created.
+type task[P any, Q interface {
+	*P
+	call()
+}] struct {
+	p  P         // function & its arguments
+	tl *taskList // where t goes when done
+	fn func()    // t.run, bound once
+}
+
+// run calls function of t & returns t to its list
+func (t *task[P, Q]) run() {
+	Q(&t.p).call()
+	t.p = *new(P) // do not retain arguments
+
+	t.tl.mu.Lock()
+	t.tl.free = append(t.tl.free, t)
+	t.tl.mu.Unlock()
+}
+
+// newTask returns run() of an idle or new task with p, from wp if it is not nil
+func newTask[P any, Q interface {
+	*P
+	call()
+}](wp *Pool, p P) func() {
+	tl := &exTasks
+	if wp != nil {
+		tl = &wp.free
+	}
+	var t *task[P, Q]
+
+	tl.mu.Lock()
+	for i := len(tl.free) - 1; i >= 0; i-- {
+		if u, ok := tl.free[i].(*task[P, Q]); ok {
+			k := len(tl.free) - 1
+			tl.free[i], tl.free[k] = tl.free[k], nil
+			tl.free = tl.free[:k]
+			t = u
+			break
+		}
+	}
+	tl.mu.Unlock()
+
+	if t == nil {
+		t = &task[P, Q]{tl: tl}
+		t.fn = t.run
+	}
+	t.p = p
+	return t.fn
+}
+
+// arguments of spawn*()
+type args3[A, B, C any] struct {
+	f func(A, B, C)
+	a A
+	b B
+	c C
+}
+
+type args4[A, B, C, D any] struct {
+	f func(A, B, C, D)
+	a A
+	b B
+	c C
+	d D
+}
+
+type args5[A, B, C, D, E any] struct {
+	f func(A, B, C, D, E)
+	a A
+	b B
+	c C
+	d D
+	e E
+}
+
+func (p *args3[A, B, C]) call() {
+	p.f(p.a, p.b, p.c)
+}
+
+func (p *args4[A, B, C, D]) call() {
+	p.f(p.a, p.b, p.c, p.d)
+}
+
+func (p *args5[A, B, C, D, E]) call() {
+	p.f(p.a, p.b, p.c, p.d, p.e)
+}
+
+// spawn3 runs f(a, b, c) concurrently via submit() with a reused task, or in a new
+// goroutine when there is no pool or executor
+func spawn3[A, B, C any](wp *Pool, f func(A, B, C), a A, b B, c C) {
+	if wp == nil && executor.Load() == nil {
+		go f(a, b, c)
+		return
+	}
+	submit(wp, newTask(wp, args3[A, B, C]{f, a, b, c}))
+}
+
+// spawn4 is like spawn3 for four arguments
+func spawn4[A, B, C, D any](wp *Pool, f func(A, B, C, D), a A, b B, c C, d D) {
+	if wp == nil && executor.Load() == nil {
+		go f(a, b, c, d)
+		return
+	}
+	submit(wp, newTask(wp, args4[A, B, C, D]{f, a, b, c, d}))
 }
 
+// spawn5 is like spawn3 for five arguments
+func spawn5[A, B, C, D, E any](wp *Pool, f func(A, B, C, D, E), a A, b B, c C, d D,
+	e E) {
+	if wp == nil && executor.Load() == nil {
+		go f(a, b, c, d, e)
+		return
+	}
+	submit(wp, newTask(wp, args5[A, B, C, D, E]{f, a, b, c, d, e}))
+}
+
+// packSw returns k with swap flag of a new-goroutine partition, for sending
+func packSw(k int, sw bool) int {
+	k <<= 1
+	if sw {
+		k++
+	}
+	return k
+}
+
+// unpackSw returns k & swap flag packed by packSw, k < 0 stays negative
+func unpackSw(v int) (int, bool) {
+	return v >> 1, v&1 != 0
+}
+
+// cancellation flags
+const (
+	stopCtx   = 1 // context is done
+	stopPanic = 2 // a sorting goroutine panicked
+	stopSeen  = 4 // a kernel saw the flags & skipped work
+)
+
 // gorFull returns true if goroutine quota is full, inlined
 //
 //go:norace
 func gorFull(sv *syncVar) bool {
-	mg := MaxGor
-	return sv.nGor >= mg
+	mg := *sv.mg
+	return sv.nGor >= mg || globalFull()
+}
+
+// globalFull returns true if process-wide goroutine budget is exhausted, inlined
+//
+//go:norace
+func globalFull() bool {
+	gm := globalMax
+	return gm != 0 && globalGor >= gm
+}
+
+// claim increases goroutine counters for a new sorting goroutine if sv's quota and
+// process-wide budget allow it, returns false otherwise
+//
+//go:norace
+func claim(sv *syncVar) bool {
+	if sv == nil || atomic.LoadUint64(&sv.nGor) >= *sv.mg || reserve(sv, 1) == 0 {
+		return false
+	}
+	atomic.AddUint64(&sv.nGor, 1)
+	return true
+}
+
+// reserve adds up to n goroutines of sv's call to the process-wide count, at most the
+// remaining budget, and returns the number added. Calls started without a budget are
+// not counted, so they never contend on the count.
+func reserve(sv *syncVar, n uint64) uint64 {
+	if !sv.glob {
+		return n
+	}
+	for {
+		g, gm := atomic.LoadUint64(&globalGor), atomic.LoadUint64(&globalMax)
+		if gm == 0 { // budget removed during the call
+			atomic.AddUint64(&globalGor, n)
+			return n
+		}
+		if g >= gm {
+			return 0
+		}
+		r := min(n, gm-g)
+		if atomic.CompareAndSwapUint64(&globalGor, g, g+r) {
+			return r
+		}
+	}
+}
+
+// unreserve removes a goroutine of sv's call from the process-wide count, inlined
+func unreserve(sv *syncVar) {
+	if sv.glob {
+		atomic.AddUint64(&globalGor, ^uint64(0))
+	}
+}
+
+// subGor decreases goroutine counters when a spawned sorting goroutine is done and
+// returns the remaining number of goroutines of its call, inlined
+func subGor(sv *syncVar) uint64 {
+	unreserve(sv)
+	return atomic.AddUint64(&sv.nGor, ^uint64(0))
+}
+
+// When a user function (like [Lesswap] or a comparator) panics, remaining work of the
+// sorting call is stopped and the panic is re-panicked in the goroutine that called
+// the sorting function, so recover() works there like with [sort.Slice]. A panic in
+// the calling goroutine keeps its original value. Only a panic in a goroutine spawned
+// by sorty reaches the caller as a *Panic, which carries the original value and the
+// stack trace of that goroutine. With [Lesswap] the collection is left as a
+// permutation of its original members.
+type Panic struct {
+	Value any    // original panic value
+	Stack []byte // stack trace of the panicking goroutine
+}
+
+// Error returns original panic value & stack trace
+func (p *Panic) Error() string {
+	return fmt.Sprintf("%v\n\n%s", p.Value, p.Stack)
+}
+
+// Unwrap returns original panic value if it is an error
+func (p *Panic) Unwrap() error {
+	err, _ := p.Value.(error)
+	return err
+}
+
+// asPanic returns p with stack trace of the panicking goroutine, unless it is a *Panic
+func asPanic(p any) *Panic {
+	if q, ok := p.(*Panic); ok {
+		return q
+	}
+	return &Panic{p, debug.Stack()}
+}
+
+// record stores p as the first panic in spawned goroutines & stops sorting
+func record(sv *syncVar, p any) {
+	if atomic.OrUint32(sv.stop, stopPanic)&stopPanic == 0 { // first one?
+		sv.pnc = asPanic(p)
+	}
+}
+
+// finish is deferred by spawned sorting goroutines: it records a panic, decreases
+// goroutine counter and signals the end if it is the last.
+func finish(sv *syncVar) {
+	if p := recover(); p != nil {
+		record(sv, p)
+	}
+	if subGor(sv) == 0 { // decrease goroutine counters
+		sv.done <- 0 // we are the last, all done
+	}
+}
+
+// collect is deferred by the calling goroutine of concurrent sorting: it stops
+// sorting if the caller panics, waits for spawned goroutines, and then re-panics
+// the caller's original panic or the first spawned goroutine's panic as *Panic.
+// Otherwise it releases sv.
+func collect(sv *syncVar) {
+	p := recover()
+	if p != nil {
+		atomic.OrUint32(sv.stop, stopPanic)
+	}
+	if atomic.AddUint64(&sv.nGor, ^uint64(0)) != 0 { // decrease goroutine counter
+		<-sv.done // we are not the last, wait
+	}
+	if p != nil {
+		panic(p)
+	}
+	if sv.pnc != nil {
+		panic(sv.pnc)
+	}
+	release(sv)
+}
+
+// stopped returns true if sorting is cancelled & marks that the caller skips work,
+// inlined
+func stopped(sv *syncVar) bool {
+	if sv == nil || sv.stop == nil || atomic.LoadUint32(sv.stop) == 0 {
+		return false
+	}
+	atomic.OrUint32(sv.stop, stopSeen)
+	return true
+}
+
+// serialVar returns nil if stop is nil, otherwise a syncVar that lets
+// single-goroutine sorting check stop without spawning goroutines.
+func serialVar(mg *uint64, stop *uint32) *syncVar {
+	if stop == nil {
+		return nil
+	}
+	return &syncVar{^uint64(0), nil, mg, stop, nil, nil, 0, false} // goroutine quota always full
+}
+
+// cancelOn sets cf.stop to a flag that is raised when ctx is done, and returns a
+// function to call after sorting, which returns ctx.Err() if a kernel saw the flag
+// and skipped work. A flag raised after sorting finished is not an error.
+func cancelOn(ctx context.Context, cf *config) func() error {
+	stop := new(uint32)
+	if ctx.Err() != nil { // already done
+		*stop = stopCtx
+	}
+	cf.stop = stop
+	release := context.AfterFunc(ctx, func() { atomic.OrUint32(stop, stopCtx) })
+
+	return func() error {
+		release()
+		if atomic.LoadUint32(stop)&stopSeen != 0 {
+			return ctx.Err()
+		}
+		return nil
+	}
+}
+
+// slice length limits for sorting functions
+type limits struct {
+	ins int // max slice length for insertion sort
+	rec int // max slice length for recursion when there is goroutine quota
+	dep int // remaining partitioning depth before falling back to heapsort
+}
+
+// parameters of a sorting call, see Sorter
+type config struct {
+	lm   limits      // default limits
+	fc   limits      // limits when sorting strings or calling Sort()
+	mg   *uint64     // max goroutines
+	nan  FloatOption // NaN handling
+	desc bool        // descending order?
+	pfx  bool        // sort strings on cached prefix keys?
+	stop *uint32     // cancellation flag, can be nil
+	wp   *Pool       // worker pool, can be nil
+}
+
+// defConfig returns package-level parameters, MaxGor can still be changed live.
+func defConfig() config {
+	return config{limits{MaxLenIns, MaxLenRec, 0},
+		limits{MaxLenInsFC, MaxLenRecFC, 0}, &MaxGor, NaNoption, false, false, nil, nil}
 }
 
 const (
@@ -98,6 +531,9 @@ const (
 	nsConc  = 8 // dual range
 )
 
+// max total distance that members can move in partial insertion sort
+const maxShift = 8
+
 // Given n ≥ 2 and slice length ≥ 2n, select n equidistant samples
 // from slice that minimizes max distance to non-selected members, inlined
 func minMaxSample(slen, n uint) (first, step, last uint) {
@@ -115,6 +551,12 @@ func minMaxSample(slen, n uint) (first, step, last uint) {
 	return
 }
 
+// maxDepth returns partitioning depth budget for sorting n members, beyond which
+// kernels fall back to heapsort for guaranteed O(n log n) time, inlined
+func maxDepth(n int) int {
+	return 2 * bits.Len(uint(n))
+}
+
 var firstFour = [8]uint32{0, 0, ^uint32(0), 0, 0, 1, 1, 0}
 var stepFour = [8]uint32{0, 0, 1, 1, 0, 0, 0, 1}
 
@@ -147,12 +589,15 @@ func extractSK(ar any) (slc sixb.InSlice, kind reflect.Kind) {
 		kind = reflect.Uint32 + reflect.Kind(unsafe.Sizeof(uint(0))>>3)
 	case reflect.Int:
 		kind = reflect.Int32 + reflect.Kind(unsafe.Sizeof(int(0))>>3)
+	case reflect.Bool:
+		kind = reflect.Uint8 // false < true
 	// map []T to sliceBias + Kind(T)
 	case reflect.Slice:
 		kind = sliceBias + tipe.Elem().Kind()
 	// other recognized types
-	case reflect.Int32, reflect.Int64, reflect.Uint32, reflect.Uint64,
-		reflect.Float32, reflect.Float64, reflect.String:
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint8,
+		reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64,
+		reflect.String:
 	default:
 		kind = reflect.Invalid
 		return
@@ -163,3 +608,21 @@ func extractSK(ar any) (slc sixb.InSlice, kind reflect.Kind) {
 	slc = sixb.InSlice{Data: p, Len: l, Cap: l}
 	return
 }
+
+// kindOf returns hardware kind of T like extractSK, without reflecting on any slice value
+//
+//go:nosplit
+func kindOf[T cmp.Ordered]() (kind reflect.Kind) {
+	kind = reflect.TypeFor[T]().Kind()
+
+	switch kind {
+	// map int/uint types to hardware type
+	case reflect.Uintptr:
+		kind = reflect.Uint32 + reflect.Kind(unsafe.Sizeof(uintptr(0))>>3)
+	case reflect.Uint:
+		kind = reflect.Uint32 + reflect.Kind(unsafe.Sizeof(uint(0))>>3)
+	case reflect.Int:
+		kind = reflect.Int32 + reflect.Kind(unsafe.Sizeof(int(0))>>3)
+	}
+	return
+}
@@ -0,0 +1,219 @@
+/*	Copyright (c) 2026, Serhat Şevki Dinçer.
+	This Source Code Form is subject to the terms of the Mozilla Public
+	License, v. 2.0. If a copy of the MPL was not distributed with this
+	file, You can obtain one at http://mozilla.org/MPL/2.0/.
+*/
+
+package sorty
+
+import (
+	"bytes"
+	"cmp"
+	"reflect"
+	"slices"
+
+	sb "github.com/jfcg/sixb/v2"
+)
+
+// identity returns 0,1,..,n-1
+func identity(n int) []int {
+	perm := make([]int, n)
+	for i := range perm {
+		perm[i] = i
+	}
+	return perm
+}
+
+// argsortP sorts keys[l:h] without NaNs in ascending order carrying perm along,
+// reverses both for descending order and sorts perm within runs of equal keys
+// for stability.
+func argsortP[K cmp.Ordered](keys []K, perm []int, l, h int, lm limits, stable bool,
+	cf *config) {
+
+	sortP(keys[l:h], perm[l:h], lm, cf.mg, cf.stop, cf.wp)
+	if cf.desc {
+		slices.Reverse(keys)
+		slices.Reverse(perm)
+	}
+	if !stable {
+		return
+	}
+	// sort perm within each run of equal keys, its distinct indices never have a
+	// small range of values for counting sort
+	for l = 0; l < len(keys); {
+		h = l + 1
+		for a := keys[l]; h < len(keys); h++ {
+			if b := keys[h]; a != b && (a == a || b == b) { // NaNs are equal
+				break
+			}
+		}
+		if h-l > 1 {
+			sortWideI(perm[l:h], nil, cf.lm, cf.mg, nil, cf.wp)
+		}
+		l = h
+	}
+}
+
+// argsortO fills perm that sorts ar.
+func argsortO[K cmp.Ordered](ar []K, perm []int, lm limits, stable bool, cf *config) {
+	argsortP(slices.Clone(ar), perm, 0, len(ar), lm, stable, cf)
+}
+
+// argsortF fills perm that sorts ar, nan option is taken into account.
+func argsortF[K sb.Float](ar []K, perm []int, stable bool, cf *config) {
+	keys := slices.Clone(ar)
+	l, h := nanPartP(keys, perm, cf.nan)
+	argsortP(keys, perm, l, h, cf.lm, stable, cf)
+}
+
+// argsortC sorts perm via cmp() on members of s. Ties are broken by index for
+// stability, which makes the ordering strict.
+func argsortC[S ~[]T, T any](s S, perm []int, cmp func(a, b T) int, lm limits,
+	stable bool, cf *config) {
+
+	sortC(perm, lm, cf.mg, func(i, k int) int {
+		c := cmp(s[i], s[k])
+		if cf.desc {
+			c = -c
+		}
+		if c == 0 && stable {
+			c = i - k
+		}
+		return c
+	}, cf.wp)
+}
+
+// argsort returns permutation that sorts ar in ascending/descending order,
+// optionally stable. Returns false for invalid input types.
+func argsort(ar any, stable bool, cf *config) ([]int, bool) {
+	slc, kind := extractSK(ar)
+	var perm []int
+	switch kind {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint8,
+		reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64,
+		reflect.String, sliceBias + reflect.Uint8:
+		perm = identity(int(slc.Len))
+	default:
+		return nil, false
+	}
+
+	switch kind {
+	case reflect.Int8:
+		argsortO(sb.Cast[int8](slc), perm, cf.lm, stable, cf)
+	case reflect.Int16:
+		argsortO(sb.Cast[int16](slc), perm, cf.lm, stable, cf)
+	case reflect.Int32:
+		argsortO(sb.Cast[int32](slc), perm, cf.lm, stable, cf)
+	case reflect.Int64:
+		argsortO(sb.Cast[int64](slc), perm, cf.lm, stable, cf)
+	case reflect.Uint8:
+		argsortO(sb.Cast[uint8](slc), perm, cf.lm, stable, cf)
+	case reflect.Uint16:
+		argsortO(sb.Cast[uint16](slc), perm, cf.lm, stable, cf)
+	case reflect.Uint32:
+		argsortO(sb.Cast[uint32](slc), perm, cf.lm, stable, cf)
+	case reflect.Uint64:
+		argsortO(sb.Cast[uint64](slc), perm, cf.lm, stable, cf)
+	case reflect.Float32:
+		argsortF(sb.Cast[float32](slc), perm, stable, cf)
+	case reflect.Float64:
+		argsortF(sb.Cast[float64](slc), perm, stable, cf)
+	case reflect.String:
+		argsortO(sb.Cast[string](slc), perm, cf.fc, stable, cf)
+	case sliceBias + reflect.Uint8: // [][]byte
+		argsortC(sb.Cast[[]byte](slc), perm, bytes.Compare, cf.fc, stable, cf)
+	}
+	return perm, true
+}
+
+// Argsort concurrently computes and returns the permutation perm that sorts ar in
+// ascending order, so ar[perm[0]] ≤ ar[perm[1]] ≤ .. without modifying ar.
+// ar's (underlying) type can be
+//
+//	[]int, []int8, []int16, []int32, []int64, []uint, []uint8, []uint16,
+//	[]uint32, []uint64, []bool, []uintptr, []float32, []float64, []string, [][]byte,
+//	[]unsafe.Pointer, []*T // for any type T
+//
+// otherwise it panics. Indices of equal members may end up in any order.
+func Argsort(ar any) []int {
+	cf := defConfig()
+	perm, ok := argsort(ar, false, &cf)
+	if !ok {
+		panic("sorty: Argsort: invalid input type")
+	}
+	return perm
+}
+
+// ArgsortStable is like [Argsort] but indices of equal members are in ascending order.
+func ArgsortStable(ar any) []int {
+	cf := defConfig()
+	perm, ok := argsort(ar, true, &cf)
+	if !ok {
+		panic("sorty: ArgsortStable: invalid input type")
+	}
+	return perm
+}
+
+// ArgsortFunc concurrently computes and returns the permutation perm that sorts s in
+// ascending order via cmp(), without modifying s. Indices of equal members may end up
+// in any order.
+func ArgsortFunc[S ~[]T, T any](s S, cmp func(a, b T) int) []int {
+	cf := defConfig()
+	perm := identity(len(s))
+	argsortC(s, perm, cmp, cf.fc, false, &cf)
+	return perm
+}
+
+// ArgsortStableFunc is like [ArgsortFunc] but indices of equal members are in
+// ascending order.
+func ArgsortStableFunc[S ~[]T, T any](s S, cmp func(a, b T) int) []int {
+	cf := defConfig()
+	perm := identity(len(s))
+	argsortC(s, perm, cmp, cf.fc, true, &cf)
+	return perm
+}
+
+// ApplyPerm reorders s in-place as s[i] = old s[perm[i]], for example to sort s with a
+// permutation returned from Argsort*(). perm must be a permutation of 0,..,len(s)-1
+// and is restored before return.
+func ApplyPerm[S ~[]T, T any](s S, perm []int) {
+	for i := range perm {
+		if perm[i] < 0 { // visited?
+			continue
+		}
+		val, j := s[i], i
+		for { // follow cycle, mark visited
+			k := perm[j]
+			perm[j] = ^k
+			if k == i {
+				s[j] = val
+				break
+			}
+			s[j] = s[k]
+			j = k
+		}
+	}
+	for i := range perm {
+		perm[i] = ^perm[i]
+	}
+}
+
+// InvertPerm inverts permutation perm in-place, so that perm[old perm[i]] = i.
+// perm must be a permutation of 0,..,len(perm)-1.
+func InvertPerm(perm []int) {
+	for i := range perm {
+		if perm[i] < 0 { // visited?
+			continue
+		}
+		prev, j := i, perm[i]
+		for j != i { // follow cycle, mark visited
+			k := perm[j]
+			perm[j] = ^prev
+			prev, j = j, k
+		}
+		perm[i] = ^prev
+	}
+	for i := range perm {
+		perm[i] = ^perm[i]
+	}
+}
@@ -7,6 +7,8 @@
 package sorty
 
 import (
+	"bytes"
+	"slices"
 	"sync/atomic"
 
 	sb "github.com/jfcg/sixb/v2"
@@ -23,6 +25,17 @@ func isSortedB(ar [][]byte) int {
 	return 0
 }
 
+// isSortedDescB returns 0 if ar is sorted in descending lexicographic
+// order, otherwise it returns i > 0 with string(ar[i]) > string(ar[i-1]), inlined
+func isSortedDescB(ar [][]byte) int {
+	for i := len(ar) - 1; i > 0; i-- {
+		if sb.String(ar[i]) > sb.String(ar[i-1]) {
+			return i
+		}
+	}
+	return 0
+}
+
 // insertion sort, inlined
 func insertionB(slc [][]byte) {
 	for h := 1; h < len(slc); h++ {
@@ -48,12 +61,47 @@ func insertionB(slc [][]byte) {
 	}
 }
 
+// presortedB returns true if slc is sorted, after reversing it if it is sorted in
+// descending order. It returns at the first out-of-order pair from the end, inlined
+func presortedB(slc [][]byte) bool {
+	if isSortedB(slc) == 0 {
+		return true
+	}
+	if isSortedDescB(slc) != 0 {
+		return false
+	}
+	slices.Reverse(slc)
+	return true
+}
+
+// partial insertion sort, gives up when members move by a total distance more than
+// maxShift. Returns true if slc is sorted.
+func partInsertionB(slc [][]byte) bool {
+	shift := 0
+	for h := 1; h < len(slc); h++ {
+		val := slc[h]
+		if !(sb.String(val) < sb.String(slc[h-1])) {
+			continue
+		}
+		l := h
+		for ; l > 0 && sb.String(val) < sb.String(slc[l-1]); l-- {
+			slc[l] = slc[l-1]
+		}
+		slc[l] = val
+		if shift += h - l; shift > maxShift {
+			return false
+		}
+	}
+	return true
+}
+
 // pivotB selects n equidistant samples from slc that minimizes max distance
-// to non-selected members, then sorts the samples and returns their median.
-// Assumes odd n, nsConc > n ≥ 3, len(slc) ≥ 2n. Returns pivot for partitioning.
+// to non-selected members, then sorts the samples and returns their median with
+// its predecessor. Assumes odd n, nsConc > n ≥ 3, len(slc) ≥ 2n. Median is the
+// pivot for partitioning.
 //
 //go:nosplit
-func pivotB(slc [][]byte, n uint) string {
+func pivotB(slc [][]byte, n uint) (string, string) {
 
 	first, step, last := minMaxSample(uint(len(slc)), n)
 
@@ -70,23 +118,24 @@ func pivotB(slc [][]byte, n uint) string {
 	}
 	insertionO(sample[:n]) // sort n samples
 
-	return sample[n>>1] // return middle sample
+	n >>= 1 // return middle sample & its predecessor
+	return sample[n-1], sample[n]
 }
 
-// partition slc, returns k with slc[:k] ≤ pivot ≤ slc[k:]
+// partition slc, returns k with slc[:k] ≤ pivot ≤ slc[k:] & whether it swapped
 // swap: slc[h] < pv ≤ slc[l]
 // swap: slc[h] ≤ pv < slc[l]
 // next: slc[l] ≤ pv ≤ slc[h]
 //
 //go:nosplit
-func partOneB(slc [][]byte, pv string) int {
-	l, h := 0, len(slc)-1
+func partOneB(slc [][]byte, pv string) (int, bool) {
+	l, h, sw := 0, len(slc)-1, false
 	goto start
 second:
 	for {
 		h--
 		if h <= l {
-			return l
+			return l, sw
 		}
 		if sb.String(slc[h]) <= pv {
 			break
@@ -94,6 +143,7 @@ second:
 	}
 swap:
 	slc[l], slc[h] = slc[h], slc[l]
+	sw = true
 next:
 	l++
 	h--
@@ -114,34 +164,54 @@ start:
 		}
 		l++
 		if h <= l {
-			return l + 1
+			return l + 1, sw
 		}
 	}
 last:
 	if l == h && sb.String(slc[h]) < pv { // classify mid element
 		l++
 	}
-	return l
+	return l, sw
+}
+
+// three-way partition slc, returns l, h with slc[:l] < pivot = slc[l:h] < slc[h:]
+func partThreeB(slc [][]byte, pv string) (l, h int) {
+	h = len(slc)
+	for i := 0; i < h; {
+		if v := slc[i]; sb.String(v) < pv {
+			slc[l], slc[i] = v, slc[l]
+			l++
+			i++
+		} else if pv < sb.String(v) {
+			h--
+			slc[i], slc[h] = slc[h], v
+		} else {
+			i++
+		}
+	}
+	return
 }
 
 // swaps elements to get slc[:l] ≤ pivot ≤ slc[h:]
-// Gap (l,h) expands until one of the intervals is fully consumed.
+// Gap (l,h) expands until one of the intervals is fully consumed. Also returns
+// whether it swapped
 // swap: slc[h] < pv ≤ slc[l]
 // swap: slc[h] ≤ pv < slc[l]
 // next: slc[l] ≤ pv ≤ slc[h]
 //
 //go:nosplit
-func partTwoB(slc [][]byte, l, h int, pv string) int {
+func partTwoB(slc [][]byte, l, h int, pv string) (int, bool) {
 	l--
+	sw := false
 	if h <= l {
-		return -1 // will not run
+		return -1, sw // will not run
 	}
 	goto start
 second:
 	for {
 		h++
 		if h >= len(slc) {
-			return l
+			return l, sw
 		}
 		if sb.String(slc[h]) <= pv {
 			break
@@ -149,15 +219,16 @@ second:
 	}
 swap:
 	slc[l], slc[h] = slc[h], slc[l]
+	sw = true
 next:
 	l--
 	h++
 start:
 	if l < 0 {
-		return h
+		return h, sw
 	}
 	if h >= len(slc) {
-		return l
+		return l, sw
 	}
 
 	if pv <= sb.String(slc[h]) { // avoid unnecessary comparisons
@@ -172,7 +243,7 @@ start:
 		}
 		l--
 		if l < 0 {
-			return h
+			return h, sw
 		}
 	}
 }
@@ -180,24 +251,37 @@ start:
 // new-goroutine partition
 //
 //go:nosplit
-func gPartOneB(ar [][]byte, pv string, ch chan int) {
-	ch <- partOneB(ar, pv)
+func gPartOneB(ar [][]byte, pv string, sv *syncVar) {
+	k, sw := partOneB(ar, pv)
+	unreserve(sv)
+	sv.done <- packSw(k, sw)
 }
 
-// partition slc in two goroutines, returns k with slc[:k] ≤ pivot ≤ slc[k:]
+// partition slc in two goroutines if budget allows, returns k with
+// slc[:k] ≤ pivot ≤ slc[k:] & whether it swapped
 //
 //go:nosplit
-func partConB(slc [][]byte, ch chan int) int {
+func partConB(slc [][]byte, sv *syncVar) (int, bool) {
 
-	pv := pivotB(slc, nsConc-1) // median-of-n pivot
+	_, pv := pivotB(slc, nsConc-1) // median-of-n pivot
 	mid := len(slc) >> 1
 	l, h := mid>>1, sb.Mean(mid, len(slc))
 
-	go gPartOneB(slc[l:h:h], pv, ch) // mid half range
+	gor := reserve(sv, 1) != 0 // budget for a new goroutine?
+	if gor {
+		spawn3(sv.wp, gPartOneB, slc[l:h:h], pv, sv) // mid half range
+	}
 
-	r := partTwoB(slc, l, h, pv) // left/right quarter ranges
+	r, sw := partTwoB(slc, l, h, pv) // left/right quarter ranges
 
-	k := l + <-ch // convert returned index to slc
+	k, sm := 0, false
+	if gor {
+		k, sm = unpackSw(<-sv.done)
+	} else {
+		k, sm = partOneB(slc[l:h:h], pv) // mid half range
+	}
+	k += l // convert returned index to slc
+	sw = sw || sm
 
 	// only one gap is possible
 	if r < mid {
@@ -205,6 +289,7 @@ func partConB(slc [][]byte, ch chan int) int {
 			if pv < sb.String(slc[r]) {
 				k--
 				slc[r], slc[k] = slc[k], slc[r]
+				sw = true
 			}
 		}
 	} else {
@@ -212,19 +297,27 @@ func partConB(slc [][]byte, ch chan int) int {
 			if sb.String(slc[r]) < pv {
 				slc[r], slc[k] = slc[k], slc[r]
 				k++
+				sw = true
 			}
 		}
 	}
-	return k
+	return k, sw
 }
 
-// short range sort function, assumes MaxLenInsFC < len(ar) <= MaxLenRecFC, recursive
-func shortB(ar [][]byte) {
+// short range sort function, assumes lm.ins < len(ar) <= lm.rec, recursive
+func shortB(ar [][]byte, lm limits) {
 start:
+	if lm.dep--; lm.dep < 0 { // too deep, avoid quadratic time
+		heapC(ar, bytes.Compare)
+		return
+	}
+	if presortedB(ar) {
+		return
+	}
 	first, step, last := minMaxSample(uint(len(ar)), 3)
 	pv := sb.Median3(sb.String(ar[first]), sb.String(ar[first+step]), sb.String(ar[last]))
 
-	k := partOneB(ar, pv)
+	k, _ := partOneB(ar, pv)
 	var aq [][]byte
 
 	if k < len(ar)-k {
@@ -235,14 +328,14 @@ start:
 		ar = ar[:k:k]
 	}
 
-	if len(aq) > MaxLenInsFC {
-		shortB(aq) // recurse on the shorter range
+	if len(aq) > lm.ins {
+		shortB(aq, lm) // recurse on the shorter range
 		goto start
 	}
 isort:
 	insertionB(aq) // at least one insertion range
 
-	if len(ar) > MaxLenInsFC {
+	if len(ar) > lm.ins {
 		goto start
 	}
 	if &ar[0] != &aq[0] {
@@ -254,21 +347,56 @@ isort:
 // new-goroutine sort function
 //
 //go:nosplit
-func gLongB(ar [][]byte, sv *syncVar) {
-	longB(ar, sv)
+func gLongB(ar [][]byte, lm limits, sv *syncVar) {
+	longB(ar, lm, sv)
 
-	if atomic.AddUint64(&sv.nGor, ^uint64(0)) == 0 { // decrease goroutine counter
+	if subGor(sv) == 0 { // decrease goroutine counters
 		sv.done <- 0 // we are the last, all done
 	}
 }
 
-// long range sort function, assumes len(ar) > MaxLenRecFC, recursive
-func longB(ar [][]byte, sv *syncVar) {
+// long range sort function, assumes len(ar) > lm.rec, recursive
+func longB(ar [][]byte, lm limits, sv *syncVar) {
 start:
-	pv := pivotB(ar, nsLong-1) // median-of-n pivot
-	k := partOneB(ar, pv)
+	if stopped(sv) {
+		return
+	}
+	if lm.dep--; lm.dep < 0 { // too deep, avoid quadratic time
+		heapC(ar, bytes.Compare)
+		return
+	}
+	if presortedB(ar) {
+		return
+	}
+	a, pv := pivotB(ar, nsLong-1) // median-of-n pivot
 	var aq [][]byte
 
+	if a == pv { // many members equal to pivot? exclude them from further sorting
+		l, h := partThreeB(ar, pv)
+		aq, ar = ar[:l:l], ar[h:]
+		if len(aq) > len(ar) {
+			aq, ar = ar, aq // ar is the longer range
+		}
+		if len(aq) > lm.rec {
+			longB(aq, lm, sv)
+		} else if len(aq) > lm.ins {
+			shortB(aq, lm)
+		} else {
+			insertionB(aq)
+		}
+		if len(ar) > lm.rec {
+			goto start
+		}
+		if len(ar) > lm.ins {
+			shortB(ar, lm)
+		} else {
+			insertionB(ar)
+		}
+		return
+	}
+
+	k, sw := partOneB(ar, pv)
+
 	if k < len(ar)-k {
 		aq = ar[:k:k]
 		ar = ar[k:] // ar is the longer range
@@ -277,57 +405,65 @@ start:
 		ar = ar[:k:k]
 	}
 
+	// balanced partition without swaps, nearly sorted input?
+	if !sw && len(aq) > len(ar)>>3 && partInsertionB(aq) && partInsertionB(ar) {
+		return
+	}
+
 	// branches below are optimal for fewer total jumps
-	if len(aq) <= MaxLenRecFC { // at least one not-long range?
+	if len(aq) <= lm.rec { // at least one not-long range?
 
-		if len(aq) > MaxLenInsFC {
-			shortB(aq)
+		if len(aq) > lm.ins {
+			shortB(aq, lm)
 		} else {
 			insertionB(aq)
 		}
 
-		if len(ar) > MaxLenRecFC { // two not-long ranges?
+		if len(ar) > lm.rec { // two not-long ranges?
 			goto start
 		}
-		shortB(ar) // we know len(ar) > MaxLenInsFC
+		shortB(ar, lm) // we know len(ar) > lm.ins
 		return
 	}
 
-	// max goroutines? not atomic but good enough
-	if sv == nil || gorFull(sv) {
-		longB(aq, sv) // recurse on the shorter range
+	// max goroutines? claim a new goroutine otherwise
+	if !claim(sv) {
+		longB(aq, lm, sv) // recurse on the shorter range
 		goto start
 	}
 
 	// new-goroutine sort on the longer range only when
 	// both ranges are big and max goroutines is not exceeded
-	atomic.AddUint64(&sv.nGor, 1) // increase goroutine counter
-	go gLongB(ar, sv)
+	spawn3(sv.wp, gLongB, ar, lm, sv)
 	ar = aq
 	goto start
 }
 
 // sortB concurrently sorts ar in ascending lexicographic order.
-func sortB(ar [][]byte) {
+func sortB(ar [][]byte, lm limits, mg *uint64, stop *uint32, wp *Pool) {
+	lm.dep = maxDepth(len(ar))
 
-	if len(ar) < 2*(MaxLenRecFC+1) || MaxGor <= 1 {
+	if len(ar) < 2*(lm.rec+1) || *mg <= 1 || globalFull() {
 
-		if len(ar) > MaxLenRecFC { // single-goroutine sorting
-			longB(ar, nil)
-		} else if len(ar) > MaxLenInsFC {
-			shortB(ar)
+		if len(ar) > lm.rec { // single-goroutine sorting
+			longB(ar, lm, serialVar(mg, stop))
+		} else if len(ar) > lm.ins {
+			shortB(ar, lm)
 		} else {
 			insertionB(ar)
 		}
 		return
 	}
 
+	if presortedB(ar) { // nothing to partition
+		return
+	}
 	// create channel only when concurrent partitioning & sorting
-	sv := syncVar{1, // number of goroutines including this
-		make(chan int)} // end signal
+	sv := newSyncVar(mg, stop, wp)
 	for {
+		lm.dep--
 		// concurrent dual partitioning with done
-		k := partConB(ar, sv.done)
+		k, sw := partConB(ar, sv)
 		var aq [][]byte
 
 		if k < len(ar)-k {
@@ -338,27 +474,35 @@ func sortB(ar [][]byte) {
 			ar = ar[:k:k]
 		}
 
+		// balanced partition without swaps, nearly sorted input?
+		if !sw && len(aq) > len(ar)>>3 && partInsertionB(aq) && partInsertionB(ar) {
+			goto wait // longer range is sorted too
+		}
+
 		// handle shorter range
-		if len(aq) > MaxLenRecFC {
-			atomic.AddUint64(&sv.nGor, 1) // increase goroutine counter
-			go gLongB(aq, &sv)
+		if len(aq) > lm.rec && claim(sv) {
+			spawn3(wp, gLongB, aq, lm, sv)
 
-		} else if len(aq) > MaxLenInsFC {
-			shortB(aq)
+		} else if len(aq) > lm.rec {
+			longB(aq, lm, sv) // goroutine quota is full
+		} else if len(aq) > lm.ins {
+			shortB(aq, lm)
 		} else {
 			insertionB(aq)
 		}
 
 		// longer range big enough? max goroutines?
-		if len(ar) < 2*(MaxLenRecFC+1) || gorFull(&sv) {
+		if len(ar) < 2*(lm.rec+1) || lm.dep <= 0 || gorFull(sv) || stopped(sv) {
 			break
 		}
 		// dual partition longer range
 	}
 
-	longB(ar, &sv) // we know len(ar) > MaxLenRecFC
+	longB(ar, lm, sv) // we know len(ar) > lm.rec
 
+wait:
 	if atomic.AddUint64(&sv.nGor, ^uint64(0)) != 0 { // decrease goroutine counter
 		<-sv.done // we are not the last, wait
 	}
+	release(sv)
 }
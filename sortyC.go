@@ -0,0 +1,486 @@
+/*	Copyright (c) 2026, Serhat Şevki Dinçer.
+	This Source Code Form is subject to the terms of the Mozilla Public
+	License, v. 2.0. If a copy of the MPL was not distributed with this
+	file, You can obtain one at http://mozilla.org/MPL/2.0/.
+*/
+
+package sorty
+
+import (
+	"slices"
+
+	sb "github.com/jfcg/sixb/v2"
+)
+
+// IsSortedFunc returns 0 if s is sorted in ascending order via cmp(), otherwise
+// it returns i > 0 with cmp(s[i], s[i-1]) < 0.
+func IsSortedFunc[S ~[]T, T any](s S, cmp func(a, b T) int) int {
+	for i := len(s) - 1; i > 0; i-- {
+		if cmp(s[i], s[i-1]) < 0 {
+			return i
+		}
+	}
+	return 0
+}
+
+// SortFunc concurrently sorts s in ascending order via cmp(), which must be a strict
+// weak ordering like the one [slices.SortFunc] expects. Unlike [Sort], comparisons &
+// swaps are done directly on s, without a [Lesswap] closure.
+func SortFunc[S ~[]T, T any](s S, cmp func(a, b T) int) {
+	sortC(s, limits{MaxLenInsFC, MaxLenRecFC, 0}, &MaxGor, cmp, nil)
+}
+
+// insertion sort with comparator, stable
+func insertionC[S ~[]T, T any](slc S, cmp func(a, b T) int) {
+	for h := 1; h < len(slc); h++ {
+		l, val := h, slc[h]
+		for ; l > 0 && cmp(val, slc[l-1]) < 0; l-- {
+			slc[l] = slc[l-1]
+		}
+		if l != h {
+			slc[l] = val
+		}
+	}
+}
+
+// presortedC returns true if slc is sorted via cmp(), after reversing it if it is
+// sorted in descending order. It returns at the first out-of-order pair from the end.
+func presortedC[S ~[]T, T any](slc S, cmp func(a, b T) int) bool {
+	if IsSortedFunc(slc, cmp) == 0 {
+		return true
+	}
+	for i := len(slc) - 1; i > 0; i-- {
+		if cmp(slc[i], slc[i-1]) > 0 {
+			return false
+		}
+	}
+	slices.Reverse(slc)
+	return true
+}
+
+// partial insertion sort with comparator, gives up when members move by a total
+// distance more than maxShift. Returns true if slc is sorted.
+func partInsertionC[S ~[]T, T any](slc S, cmp func(a, b T) int) bool {
+	shift := 0
+	for h := 1; h < len(slc); h++ {
+		val := slc[h]
+		if cmp(val, slc[h-1]) >= 0 {
+			continue
+		}
+		l := h
+		for ; l > 0 && cmp(val, slc[l-1]) < 0; l-- {
+			slc[l] = slc[l-1]
+		}
+		slc[l] = val
+		if shift += h - l; shift > maxShift {
+			return false
+		}
+	}
+	return true
+}
+
+// siftC moves slc[i] down the max-heap slc via cmp()
+func siftC[S ~[]T, T any](slc S, i int, cmp func(a, b T) int) {
+	for c := 2*i + 1; c < len(slc); c = 2*i + 1 {
+		if c+1 < len(slc) && cmp(slc[c], slc[c+1]) < 0 {
+			c++
+		}
+		if cmp(slc[i], slc[c]) >= 0 {
+			return
+		}
+		slc[i], slc[c] = slc[c], slc[i]
+		i = c
+	}
+}
+
+// heapsort with comparator, used when partitioning gets too deep
+func heapC[S ~[]T, T any](slc S, cmp func(a, b T) int) {
+	for i := len(slc)>>1 - 1; i >= 0; i-- {
+		siftC(slc, i, cmp)
+	}
+	for h := len(slc) - 1; h > 0; h-- {
+		slc[0], slc[h] = slc[h], slc[0]
+		siftC(slc[:h], 0, cmp)
+	}
+}
+
+// median3C returns median of a, b, c via cmp()
+func median3C[T any](a, b, c T, cmp func(a, b T) int) T {
+	if cmp(b, a) < 0 {
+		a, b = b, a
+	}
+	if cmp(c, b) < 0 {
+		b = c
+		if cmp(b, a) < 0 {
+			b = a
+		}
+	}
+	return b
+}
+
+// pivotC selects n equidistant samples from slc that minimizes max distance
+// to non-selected members, then sorts the samples and returns their median.
+// Assumes odd n, nsConc > n ≥ 3, len(slc) ≥ 2n. Returns pivot for partitioning.
+func pivotC[S ~[]T, T any](slc S, n uint, cmp func(a, b T) int) T {
+
+	first, step, last := minMaxSample(uint(len(slc)), n)
+
+	var sample [nsConc - 1]T
+	a, b := slc[first], slc[last]
+	if cmp(b, a) < 0 {
+		a, b = b, a
+	}
+	sample[0], sample[n-1] = a, b
+
+	for i := n - 2; i > 0; i-- {
+		last -= step
+		sample[i] = slc[last]
+	}
+	insertionC(sample[:n], cmp) // sort n samples
+
+	return sample[n>>1] // return middle sample
+}
+
+// partition slc, returns k with slc[:k] ≤ pivot ≤ slc[k:] & whether it swapped
+// swap: slc[h] < pv ≤ slc[l]
+// swap: slc[h] ≤ pv < slc[l]
+// next: slc[l] ≤ pv ≤ slc[h]
+func partOneC[S ~[]T, T any](slc S, pv T, cmp func(a, b T) int) (int, bool) {
+	l, h, sw := 0, len(slc)-1, false
+	goto start
+second:
+	for {
+		h--
+		if h <= l {
+			return l, sw
+		}
+		if cmp(slc[h], pv) <= 0 {
+			break
+		}
+	}
+swap:
+	slc[l], slc[h] = slc[h], slc[l]
+	sw = true
+next:
+	l++
+	h--
+start:
+	if h <= l {
+		goto last
+	}
+
+	if cmp(pv, slc[h]) <= 0 { // avoid unnecessary comparisons
+		if cmp(pv, slc[l]) < 0 { // extend ranges in balance
+			goto second
+		}
+		goto next
+	}
+	for {
+		if cmp(pv, slc[l]) <= 0 {
+			goto swap
+		}
+		l++
+		if h <= l {
+			return l + 1, sw
+		}
+	}
+last:
+	if l == h && cmp(slc[h], pv) < 0 { // classify mid element
+		l++
+	}
+	return l, sw
+}
+
+// swaps elements to get slc[:l] ≤ pivot ≤ slc[h:]
+// Gap (l,h) expands until one of the intervals is fully consumed. Also returns
+// whether it swapped
+// swap: slc[h] < pv ≤ slc[l]
+// swap: slc[h] ≤ pv < slc[l]
+// next: slc[l] ≤ pv ≤ slc[h]
+func partTwoC[S ~[]T, T any](slc S, l, h int, pv T, cmp func(a, b T) int) (int, bool) {
+	l--
+	sw := false
+	if h <= l {
+		return -1, sw // will not run
+	}
+	goto start
+second:
+	for {
+		h++
+		if h >= len(slc) {
+			return l, sw
+		}
+		if cmp(slc[h], pv) <= 0 {
+			break
+		}
+	}
+swap:
+	slc[l], slc[h] = slc[h], slc[l]
+	sw = true
+next:
+	l--
+	h++
+start:
+	if l < 0 {
+		return h, sw
+	}
+	if h >= len(slc) {
+		return l, sw
+	}
+
+	if cmp(pv, slc[h]) <= 0 { // avoid unnecessary comparisons
+		if cmp(pv, slc[l]) < 0 { // extend ranges in balance
+			goto second
+		}
+		goto next
+	}
+	for {
+		if cmp(pv, slc[l]) <= 0 {
+			goto swap
+		}
+		l--
+		if l < 0 {
+			return h, sw
+		}
+	}
+}
+
+// new-goroutine partition, sends -1 if cmp() panics
+func gPartOneC[S ~[]T, T any](ar S, pv T, sv *syncVar, cmp func(a, b T) int) {
+	k := -1
+	defer func() {
+		if p := recover(); p != nil {
+			record(sv, p)
+		}
+		unreserve(sv)
+		sv.done <- k
+	}()
+	k = packSw(partOneC(ar, pv, cmp))
+}
+
+// partition slc in two goroutines if budget allows, returns k with
+// slc[:k] ≤ pivot ≤ slc[k:] & whether it swapped, or -1 if cmp() panicked in the
+// new goroutine
+func partConC[S ~[]T, T any](slc S, sv *syncVar, cmp func(a, b T) int) (int, bool) {
+
+	pv := pivotC(slc, nsConc-1, cmp) // median-of-n pivot
+	mid := len(slc) >> 1
+	l, h := mid>>1, sb.Mean(mid, len(slc))
+
+	gor := reserve(sv, 1) != 0 // budget for a new goroutine?
+	if gor {
+		spawn4(sv.wp, gPartOneC, slc[l:h:h], pv, sv, cmp) // mid half range
+	}
+
+	k := -2 // not received yet
+	defer func() {
+		if k == -2 && gor { // partTwoC() panicked, wait for gPartOneC()
+			<-sv.done
+		}
+	}()
+	r, sw := partTwoC(slc, l, h, pv, cmp) // left/right quarter ranges
+
+	sm := false
+	if !gor {
+		k, sm = partOneC(slc[l:h:h], pv, cmp) // mid half range
+	} else if k, sm = unpackSw(<-sv.done); k < 0 {
+		return k, sw
+	}
+	k += l // convert returned index to slc
+	sw = sw || sm
+
+	// only one gap is possible
+	if r < mid {
+		for ; 0 <= r; r-- { // gap left in low range?
+			if cmp(pv, slc[r]) < 0 {
+				k--
+				slc[r], slc[k] = slc[k], slc[r]
+				sw = true
+			}
+		}
+	} else {
+		for ; r < len(slc); r++ { // gap left in high range?
+			if cmp(slc[r], pv) < 0 {
+				slc[r], slc[k] = slc[k], slc[r]
+				k++
+				sw = true
+			}
+		}
+	}
+	return k, sw
+}
+
+// short range sort function, assumes lm.ins < len(ar) <= lm.rec, recursive
+func shortC[S ~[]T, T any](ar S, lm limits, cmp func(a, b T) int) {
+start:
+	if lm.dep--; lm.dep < 0 { // too deep, avoid quadratic time
+		heapC(ar, cmp)
+		return
+	}
+	if presortedC(ar, cmp) {
+		return
+	}
+	first, step, last := minMaxSample(uint(len(ar)), 3)
+	pv := median3C(ar[first], ar[first+step], ar[last], cmp)
+
+	k, _ := partOneC(ar, pv, cmp)
+	var aq S
+
+	if k < len(ar)-k {
+		aq = ar[:k:k]
+		ar = ar[k:] // ar is the longer range
+	} else {
+		aq = ar[k:]
+		ar = ar[:k:k]
+	}
+
+	if len(aq) > lm.ins {
+		shortC(aq, lm, cmp) // recurse on the shorter range
+		goto start
+	}
+isort:
+	insertionC(aq, cmp) // at least one insertion range
+
+	if len(ar) > lm.ins {
+		goto start
+	}
+	if &ar[0] != &aq[0] {
+		aq = ar
+		goto isort // two insertion ranges
+	}
+}
+
+// new-goroutine sort function
+func gLongC[S ~[]T, T any](ar S, lm limits, sv *syncVar, cmp func(a, b T) int) {
+	defer finish(sv)
+	longC(ar, lm, sv, cmp)
+}
+
+// long range sort function, assumes len(ar) > lm.rec, recursive
+func longC[S ~[]T, T any](ar S, lm limits, sv *syncVar, cmp func(a, b T) int) {
+start:
+	if stopped(sv) {
+		return
+	}
+	if lm.dep--; lm.dep < 0 { // too deep, avoid quadratic time
+		heapC(ar, cmp)
+		return
+	}
+	if presortedC(ar, cmp) {
+		return
+	}
+	pv := pivotC(ar, nsLong-1, cmp) // median-of-n pivot
+	k, sw := partOneC(ar, pv, cmp)
+	var aq S
+
+	if k < len(ar)-k {
+		aq = ar[:k:k]
+		ar = ar[k:] // ar is the longer range
+	} else {
+		aq = ar[k:]
+		ar = ar[:k:k]
+	}
+
+	// balanced partition without swaps, nearly sorted input?
+	if !sw && len(aq) > len(ar)>>3 &&
+		partInsertionC(aq, cmp) && partInsertionC(ar, cmp) {
+		return
+	}
+
+	// branches below are optimal for fewer total jumps
+	if len(aq) <= lm.rec { // at least one not-long range?
+
+		if len(aq) > lm.ins {
+			shortC(aq, lm, cmp)
+		} else {
+			insertionC(aq, cmp)
+		}
+
+		if len(ar) > lm.rec { // two not-long ranges?
+			goto start
+		}
+		shortC(ar, lm, cmp) // we know len(ar) > lm.ins
+		return
+	}
+
+	// max goroutines? claim a new goroutine otherwise
+	if !claim(sv) {
+		longC(aq, lm, sv, cmp) // recurse on the shorter range
+		goto start
+	}
+
+	// new-goroutine sort on the longer range only when
+	// both ranges are big and max goroutines is not exceeded
+	spawn4(sv.wp, gLongC, ar, lm, sv, cmp)
+	ar = aq
+	goto start
+}
+
+// sortC concurrently sorts ar in ascending order via cmp().
+func sortC[S ~[]T, T any](ar S, lm limits, mg *uint64, cmp func(a, b T) int, wp *Pool) {
+	lm.dep = maxDepth(len(ar))
+
+	if len(ar) < 2*(lm.rec+1) || *mg <= 1 || globalFull() {
+
+		if len(ar) > lm.rec { // single-goroutine sorting
+			longC(ar, lm, nil, cmp)
+		} else if len(ar) > lm.ins {
+			shortC(ar, lm, cmp)
+		} else {
+			insertionC(ar, cmp)
+		}
+		return
+	}
+
+	if presortedC(ar, cmp) { // nothing to partition
+		return
+	}
+	// create channel only when concurrent partitioning & sorting
+	sv := newSyncVar(mg, nil, wp)
+	ownStop(sv) // to stop all goroutines when cmp() panics
+	defer collect(sv)
+
+	for {
+		lm.dep--
+		// concurrent dual partitioning with done
+		k, sw := partConC(ar, sv, cmp)
+		if k < 0 { // cmp() panicked in gPartOneC()
+			return
+		}
+		var aq S
+
+		if k < len(ar)-k {
+			aq = ar[:k:k]
+			ar = ar[k:] // ar is the longer range
+		} else {
+			aq = ar[k:]
+			ar = ar[:k:k]
+		}
+
+		// balanced partition without swaps, nearly sorted input?
+		if !sw && len(aq) > len(ar)>>3 &&
+			partInsertionC(aq, cmp) && partInsertionC(ar, cmp) {
+			return // longer range is sorted too
+		}
+
+		// handle shorter range
+		if len(aq) > lm.rec && claim(sv) {
+			spawn4(sv.wp, gLongC, aq, lm, sv, cmp)
+
+		} else if len(aq) > lm.rec {
+			longC(aq, lm, sv, cmp) // goroutine quota is full
+		} else if len(aq) > lm.ins {
+			shortC(aq, lm, cmp)
+		} else {
+			insertionC(aq, cmp)
+		}
+
+		// longer range big enough? max goroutines?
+		if len(ar) < 2*(lm.rec+1) || lm.dep <= 0 || gorFull(sv) || stopped(sv) {
+			break
+		}
+		// dual partition longer range
+	}
+
+	longC(ar, lm, sv, cmp) // we know len(ar) > lm.rec
+}
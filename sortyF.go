@@ -13,32 +13,65 @@ import (
 )
 
 // isSortedF returns 0 if slc is sorted in ascending order, otherwise it returns i > 0
-// with slc[i] < slc[i-1] or either one is a NaN. NaNoption is taken into account.
-func isSortedF[S ~[]T, T sb.Float](slc S) int {
+// with slc[i] < slc[i-1] or either one is a NaN. nan option is taken into account.
+func isSortedF[S ~[]T, T sb.Float](slc S, nan FloatOption) int {
 	l, h := 0, len(slc)-1
-	if NaNoption == NaNlarge { // ignore NaNs at the end
+	if nan == NaNlarge { // ignore NaNs at the end
 		for ; l <= h; h-- {
 			if x := slc[h]; x == x {
 				break
 			}
 		}
-	} else if NaNoption == NaNsmall { // ignore NaNs at the start
+	} else if nan == NaNsmall { // ignore NaNs at the start
 		for ; l <= h; l++ {
 			if x := slc[l]; x == x {
 				break
 			}
 		}
 	}
-	return isSortedO(slc[l : h+1])
+	if i := isSortedO(slc[l : h+1]); i > 0 {
+		return l + i
+	}
+	return 0
+}
+
+// isSortedDescF returns 0 if slc is sorted in descending order, otherwise it returns
+// i > 0 with slc[i] > slc[i-1] or either one is a NaN. nan option is taken into account.
+func isSortedDescF[S ~[]T, T sb.Float](slc S, nan FloatOption) int {
+	l, h := 0, len(slc)-1
+	if nan == NaNlarge { // ignore NaNs at the start
+		for ; l <= h; l++ {
+			if x := slc[l]; x == x {
+				break
+			}
+		}
+	} else if nan == NaNsmall { // ignore NaNs at the end
+		for ; l <= h; h-- {
+			if x := slc[h]; x == x {
+				break
+			}
+		}
+	}
+	if i := isSortedDescO(slc[l : h+1]); i > 0 {
+		return l + i
+	}
+	return 0
 }
 
-// short range sort function, assumes MaxLenIns < len(ar) <= MaxLenRec, recursive
-func shortF[S ~[]T, T sb.Float](ar S) {
+// short range sort function, assumes lm.ins < len(ar) <= lm.rec, recursive
+func shortF[S ~[]T, T sb.Float](ar S, lm limits) {
 start:
+	if lm.dep--; lm.dep < 0 { // too deep, avoid quadratic time
+		heapO(ar)
+		return
+	}
+	if presortedO(ar) {
+		return
+	}
 	first, step, last := minMaxSample(uint(len(ar)), 3)
 	pv := sb.Median3(ar[first], ar[first+step], ar[last])
 
-	k := partOneO(ar, pv)
+	k, _ := partOneO(ar, pv)
 	var aq S
 
 	if k < len(ar)-k {
@@ -49,14 +82,14 @@ start:
 		ar = ar[:k:k]
 	}
 
-	if len(aq) > MaxLenIns {
-		shortF(aq) // recurse on the shorter range
+	if len(aq) > lm.ins {
+		shortF(aq, lm) // recurse on the shorter range
 		goto start
 	}
 isort:
 	insertionO(aq) // at least one insertion range
 
-	if len(ar) > MaxLenIns {
+	if len(ar) > lm.ins {
 		goto start
 	}
 	if &ar[0] != &aq[0] {
@@ -68,21 +101,56 @@ isort:
 // new-goroutine sort function
 //
 //go:nosplit
-func gLongF[S ~[]T, T sb.Float](ar S, sv *syncVar) {
-	longF(ar, sv)
+func gLongF[S ~[]T, T sb.Float](ar S, lm limits, sv *syncVar) {
+	longF(ar, lm, sv)
 
-	if atomic.AddUint64(&sv.nGor, ^uint64(0)) == 0 { // decrease goroutine counter
+	if subGor(sv) == 0 { // decrease goroutine counters
 		sv.done <- 0 // we are the last, all done
 	}
 }
 
-// long range sort function, assumes len(ar) > MaxLenRec, recursive
-func longF[S ~[]T, T sb.Float](ar S, sv *syncVar) {
+// long range sort function, assumes len(ar) > lm.rec, recursive
+func longF[S ~[]T, T sb.Float](ar S, lm limits, sv *syncVar) {
 start:
-	_, pv := pivotO(ar, nsLong-1) // median-of-n pivot
-	k := partOneO(ar, pv)
+	if stopped(sv) {
+		return
+	}
+	if lm.dep--; lm.dep < 0 { // too deep, avoid quadratic time
+		heapO(ar)
+		return
+	}
+	if presortedO(ar) {
+		return
+	}
+	a, pv := pivotO(ar, nsLong-1) // median-of-n pivot
 	var aq S
 
+	if a == pv { // many members equal to pivot? exclude them from further sorting
+		l, h := partThreeO(ar, pv)
+		aq, ar = ar[:l:l], ar[h:]
+		if len(aq) > len(ar) {
+			aq, ar = ar, aq // ar is the longer range
+		}
+		if len(aq) > lm.rec {
+			longF(aq, lm, sv)
+		} else if len(aq) > lm.ins {
+			shortF(aq, lm)
+		} else {
+			insertionO(aq)
+		}
+		if len(ar) > lm.rec {
+			goto start
+		}
+		if len(ar) > lm.ins {
+			shortF(ar, lm)
+		} else {
+			insertionO(ar)
+		}
+		return
+	}
+
+	k, sw := partOneO(ar, pv)
+
 	if k < len(ar)-k {
 		aq = ar[:k:k]
 		ar = ar[k:] // ar is the longer range
@@ -91,42 +159,45 @@ start:
 		ar = ar[:k:k]
 	}
 
+	// balanced partition without swaps, nearly sorted input?
+	if !sw && len(aq) > len(ar)>>3 && partInsertionO(aq) && partInsertionO(ar) {
+		return
+	}
+
 	// branches below are optimal for fewer total jumps
-	if len(aq) <= MaxLenRec { // at least one not-long range?
+	if len(aq) <= lm.rec { // at least one not-long range?
 
-		if len(aq) > MaxLenIns {
-			shortF(aq)
+		if len(aq) > lm.ins {
+			shortF(aq, lm)
 		} else {
 			insertionO(aq)
 		}
 
-		if len(ar) > MaxLenRec { // two not-long ranges?
+		if len(ar) > lm.rec { // two not-long ranges?
 			goto start
 		}
-		shortF(ar) // we know len(ar) > MaxLenIns
+		shortF(ar, lm) // we know len(ar) > lm.ins
 		return
 	}
 
-	// max goroutines? not atomic but good enough
-	if sv == nil || gorFull(sv) {
-		longF(aq, sv) // recurse on the shorter range
+	// max goroutines? claim a new goroutine otherwise
+	if !claim(sv) {
+		longF(aq, lm, sv) // recurse on the shorter range
 		goto start
 	}
 
 	// new-goroutine sort on the longer range only when
 	// both ranges are big and max goroutines is not exceeded
-	atomic.AddUint64(&sv.nGor, 1) // increase goroutine counter
-	go gLongF(ar, sv)
+	spawn3(sv.wp, gLongF, ar, lm, sv)
 	ar = aq
 	goto start
 }
 
-// sortF concurrently sorts ar in ascending order.
-//
-//go:nosplit
-func sortF[S ~[]T, T sb.Float](ar S) {
-	l, h := 0, len(ar)-1
-	if NaNoption == NaNlarge { // move NaNs to the end
+// nanPart moves NaNs to the start/end of ar according to nan option and returns the
+// range ar[l:h] of the rest.
+func nanPart[S ~[]T, T sb.Float](ar S, nan FloatOption) (l, h int) {
+	h = len(ar) - 1
+	if nan == NaNlarge { // move NaNs to the end
 		for l <= h {
 			x := ar[h]
 			if x != x {
@@ -140,8 +211,8 @@ func sortF[S ~[]T, T sb.Float](ar S) {
 			}
 			l++
 		}
-		ar = ar[:h+1]
-	} else if NaNoption == NaNsmall { // move NaNs to the start
+		return 0, h + 1
+	} else if nan == NaNsmall { // move NaNs to the start
 		for l <= h {
 			y := ar[l]
 			if y != y {
@@ -155,28 +226,47 @@ func sortF[S ~[]T, T sb.Float](ar S) {
 			}
 			h--
 		}
-		ar = ar[l:]
 	}
+	return l, len(ar)
+}
 
-	if len(ar) < 2*(MaxLenRec+1) || MaxGor <= 1 {
+// sortF concurrently sorts ar in ascending order. nan option is taken into account.
+// It radix sorts ar if it is long enough and buf can hold it.
+//
+//go:nosplit
+func sortF[S ~[]T, T sb.Float](ar, buf S, lm limits, mg *uint64, nan FloatOption,
+	stop *uint32, wp *Pool) {
 
-		if len(ar) > MaxLenRec { // single-goroutine sorting
-			longF(ar, nil)
-		} else if len(ar) > MaxLenIns {
-			shortF(ar)
+	l, h := nanPart(ar, nan)
+	ar = ar[l:h]
+	if len(ar) >= MinLenRadix && len(buf) >= len(ar) {
+		radixF(ar, buf, mg, stop, wp)
+		return
+	}
+	lm.dep = maxDepth(len(ar))
+
+	if len(ar) < 2*(lm.rec+1) || *mg <= 1 || globalFull() {
+
+		if len(ar) > lm.rec { // single-goroutine sorting
+			longF(ar, lm, serialVar(mg, stop))
+		} else if len(ar) > lm.ins {
+			shortF(ar, lm)
 		} else {
 			insertionO(ar)
 		}
 		return
 	}
 
+	if presortedO(ar) { // nothing to partition
+		return
+	}
 	// create channel only when concurrent partitioning & sorting
-	sv := syncVar{1, // number of goroutines including this
-		make(chan int)} // end signal
+	sv := newSyncVar(mg, stop, wp)
 	for {
+		lm.dep--
 		// concurrent dual partitioning with done
 		_, pv := pivotO(ar, nsConc-1) // median-of-n pivot
-		k := partConO(ar, pv, sv.done)
+		k, sw := partConO(ar, pv, sv)
 		var aq S
 
 		if k < len(ar)-k {
@@ -187,27 +277,35 @@ func sortF[S ~[]T, T sb.Float](ar S) {
 			ar = ar[:k:k]
 		}
 
+		// balanced partition without swaps, nearly sorted input?
+		if !sw && len(aq) > len(ar)>>3 && partInsertionO(aq) && partInsertionO(ar) {
+			goto wait // longer range is sorted too
+		}
+
 		// handle shorter range
-		if len(aq) > MaxLenRec {
-			atomic.AddUint64(&sv.nGor, 1) // increase goroutine counter
-			go gLongF(aq, &sv)
+		if len(aq) > lm.rec && claim(sv) {
+			spawn3(wp, gLongF, aq, lm, sv)
 
-		} else if len(aq) > MaxLenIns {
-			shortF(aq)
+		} else if len(aq) > lm.rec {
+			longF(aq, lm, sv) // goroutine quota is full
+		} else if len(aq) > lm.ins {
+			shortF(aq, lm)
 		} else {
 			insertionO(aq)
 		}
 
 		// longer range big enough? max goroutines?
-		if len(ar) < 2*(MaxLenRec+1) || gorFull(&sv) {
+		if len(ar) < 2*(lm.rec+1) || lm.dep <= 0 || gorFull(sv) || stopped(sv) {
 			break
 		}
 		// dual partition longer range
 	}
 
-	longF(ar, &sv) // we know len(ar) > MaxLenRec
+	longF(ar, lm, sv) // we know len(ar) > lm.rec
 
+wait:
 	if atomic.AddUint64(&sv.nGor, ^uint64(0)) != 0 { // decrease goroutine counter
 		<-sv.done // we are not the last, wait
 	}
+	release(sv)
 }
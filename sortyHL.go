@@ -7,6 +7,7 @@
 package sorty
 
 import (
+	"slices"
 	"sync/atomic"
 
 	"github.com/jfcg/sixb/v2"
@@ -27,6 +28,17 @@ func isSortedHL[S ~[]T, T hasLen](ar S) int {
 	return 0
 }
 
+// isSortedDescHL returns 0 if ar is sorted by length in descending
+// order, otherwise it returns i > 0 with len(ar[i]) > len(ar[i-1]), inlined
+func isSortedDescHL[S ~[]T, T hasLen](ar S) int {
+	for i := len(ar) - 1; i > 0; i-- {
+		if len(ar[i]) > len(ar[i-1]) {
+			return i
+		}
+	}
+	return 0
+}
+
 // insertion sort, inlined
 func insertionHL[S ~[]T, T hasLen](slc S) {
 	for h := 1; h < len(slc); h++ {
@@ -52,12 +64,47 @@ func insertionHL[S ~[]T, T hasLen](slc S) {
 	}
 }
 
+// presortedHL returns true if slc is sorted by length, after reversing it if it is
+// sorted in descending order. It returns at the first out-of-order pair from the end
+func presortedHL[S ~[]T, T hasLen](slc S) bool {
+	if isSortedHL(slc) == 0 {
+		return true
+	}
+	if isSortedDescHL(slc) != 0 {
+		return false
+	}
+	slices.Reverse(slc)
+	return true
+}
+
+// partial insertion sort, gives up when members move by a total distance more than
+// maxShift. Returns true if slc is sorted.
+func partInsertionHL[S ~[]T, T hasLen](slc S) bool {
+	shift := 0
+	for h := 1; h < len(slc); h++ {
+		val := slc[h]
+		if !(len(val) < len(slc[h-1])) {
+			continue
+		}
+		l := h
+		for ; l > 0 && len(val) < len(slc[l-1]); l-- {
+			slc[l] = slc[l-1]
+		}
+		slc[l] = val
+		if shift += h - l; shift > maxShift {
+			return false
+		}
+	}
+	return true
+}
+
 // pivotHL selects n equidistant samples from slc that minimizes max distance
-// to non-selected members, then sorts the samples and returns their median.
-// Assumes even n, nsConc ≥ n ≥ 2, len(slc) ≥ 2n. Returns pivot for partitioning.
+// to non-selected members, then sorts the samples and returns the middle two.
+// Assumes even n, nsConc ≥ n ≥ 2, len(slc) ≥ 2n. Their mean is the pivot for
+// partitioning.
 //
 //go:nosplit
-func pivotHL[S ~[]T, T hasLen](slc S, n uint) int {
+func pivotHL[S ~[]T, T hasLen](slc S, n uint) (int, int) {
 
 	first, step, last := minMaxSample(uint(len(slc)), n)
 
@@ -74,24 +121,24 @@ func pivotHL[S ~[]T, T hasLen](slc S, n uint) int {
 	}
 	insertionO(sample[:n]) // sort n samples
 
-	n >>= 1 // return mean of middle two samples
-	return sixb.Mean(sample[n-1], sample[n])
+	n >>= 1 // return middle two samples
+	return sample[n-1], sample[n]
 }
 
-// partition slc, returns k with slc[:k] ≤ pivot ≤ slc[k:]
+// partition slc, returns k with slc[:k] ≤ pivot ≤ slc[k:] & whether it swapped
 // swap: slc[h] < pv ≤ slc[l]
 // swap: slc[h] ≤ pv < slc[l]
 // next: slc[l] ≤ pv ≤ slc[h]
 //
 //go:nosplit
-func partOneHL[S ~[]T, T hasLen](slc S, pv int) int {
-	l, h := 0, len(slc)-1
+func partOneHL[S ~[]T, T hasLen](slc S, pv int) (int, bool) {
+	l, h, sw := 0, len(slc)-1, false
 	goto start
 second:
 	for {
 		h--
 		if h <= l {
-			return l
+			return l, sw
 		}
 		if len(slc[h]) <= pv {
 			break
@@ -99,6 +146,7 @@ second:
 	}
 swap:
 	slc[l], slc[h] = slc[h], slc[l]
+	sw = true
 next:
 	l++
 	h--
@@ -119,34 +167,55 @@ start:
 		}
 		l++
 		if h <= l {
-			return l + 1
+			return l + 1, sw
 		}
 	}
 last:
 	if l == h && len(slc[h]) < pv { // classify mid element
 		l++
 	}
-	return l
+	return l, sw
+}
+
+// three-way partition slc by length, returns l, h with
+// len(slc[:l]) < pivot = len(slc[l:h]) < len(slc[h:])
+func partThreeHL[S ~[]T, T hasLen](slc S, pv int) (l, h int) {
+	h = len(slc)
+	for i := 0; i < h; {
+		if v := slc[i]; len(v) < pv {
+			slc[l], slc[i] = v, slc[l]
+			l++
+			i++
+		} else if pv < len(v) {
+			h--
+			slc[i], slc[h] = slc[h], v
+		} else {
+			i++
+		}
+	}
+	return
 }
 
 // swaps elements to get slc[:l] ≤ pivot ≤ slc[h:]
-// Gap (l,h) expands until one of the intervals is fully consumed.
+// Gap (l,h) expands until one of the intervals is fully consumed. Also returns
+// whether it swapped
 // swap: slc[h] < pv ≤ slc[l]
 // swap: slc[h] ≤ pv < slc[l]
 // next: slc[l] ≤ pv ≤ slc[h]
 //
 //go:nosplit
-func partTwoHL[S ~[]T, T hasLen](slc S, l, h int, pv int) int {
+func partTwoHL[S ~[]T, T hasLen](slc S, l, h int, pv int) (int, bool) {
 	l--
+	sw := false
 	if h <= l {
-		return -1 // will not run
+		return -1, sw // will not run
 	}
 	goto start
 second:
 	for {
 		h++
 		if h >= len(slc) {
-			return l
+			return l, sw
 		}
 		if len(slc[h]) <= pv {
 			break
@@ -154,15 +223,16 @@ second:
 	}
 swap:
 	slc[l], slc[h] = slc[h], slc[l]
+	sw = true
 next:
 	l--
 	h++
 start:
 	if l < 0 {
-		return h
+		return h, sw
 	}
 	if h >= len(slc) {
-		return l
+		return l, sw
 	}
 
 	if pv <= len(slc[h]) { // avoid unnecessary comparisons
@@ -177,7 +247,7 @@ start:
 		}
 		l--
 		if l < 0 {
-			return h
+			return h, sw
 		}
 	}
 }
@@ -185,24 +255,38 @@ start:
 // new-goroutine partition
 //
 //go:nosplit
-func gPartOneHL[S ~[]T, T hasLen](ar S, pv int, ch chan int) {
-	ch <- partOneHL(ar, pv)
+func gPartOneHL[S ~[]T, T hasLen](ar S, pv int, sv *syncVar) {
+	k, sw := partOneHL(ar, pv)
+	unreserve(sv)
+	sv.done <- packSw(k, sw)
 }
 
-// partition slc in two goroutines, returns k with slc[:k] ≤ pivot ≤ slc[k:]
+// partition slc in two goroutines if budget allows, returns k with
+// slc[:k] ≤ pivot ≤ slc[k:] & whether it swapped
 //
 //go:nosplit
-func partConHL[S ~[]T, T hasLen](slc S, ch chan int) int {
+func partConHL[S ~[]T, T hasLen](slc S, sv *syncVar) (int, bool) {
 
-	pv := pivotHL(slc, nsConc) // median-of-n pivot
+	a, b := pivotHL(slc, nsConc)
+	pv := sixb.Mean(a, b) // median-of-n pivot
 	mid := len(slc) >> 1
 	l, h := mid>>1, sixb.Mean(mid, len(slc))
 
-	go gPartOneHL(slc[l:h:h], pv, ch) // mid half range
+	gor := reserve(sv, 1) != 0 // budget for a new goroutine?
+	if gor {
+		spawn3(sv.wp, gPartOneHL, slc[l:h:h], pv, sv) // mid half range
+	}
 
-	r := partTwoHL(slc, l, h, pv) // left/right quarter ranges
+	r, sw := partTwoHL(slc, l, h, pv) // left/right quarter ranges
 
-	k := l + <-ch // convert returned index to slc
+	k, sm := 0, false
+	if gor {
+		k, sm = unpackSw(<-sv.done)
+	} else {
+		k, sm = partOneHL(slc[l:h:h], pv) // mid half range
+	}
+	k += l // convert returned index to slc
+	sw = sw || sm
 
 	// only one gap is possible
 	if r < mid {
@@ -210,6 +294,7 @@ func partConHL[S ~[]T, T hasLen](slc S, ch chan int) int {
 			if pv < len(slc[r]) {
 				k--
 				slc[r], slc[k] = slc[k], slc[r]
+				sw = true
 			}
 		}
 	} else {
@@ -217,20 +302,28 @@ func partConHL[S ~[]T, T hasLen](slc S, ch chan int) int {
 			if len(slc[r]) < pv {
 				slc[r], slc[k] = slc[k], slc[r]
 				k++
+				sw = true
 			}
 		}
 	}
-	return k
+	return k, sw
 }
 
-// short range sort function, assumes MaxLenIns < len(ar) <= MaxLenRec, recursive
-func shortHL[S ~[]T, T hasLen](ar S) {
+// short range sort function, assumes lm.ins < len(ar) <= lm.rec, recursive
+func shortHL[S ~[]T, T hasLen](ar S, lm limits) {
 start:
+	if lm.dep--; lm.dep < 0 { // too deep, avoid quadratic time
+		heapC(ar, cmpLen[T])
+		return
+	}
+	if presortedHL(ar) {
+		return
+	}
 	first, step := minMaxFour(uint32(len(ar)))
 	pv := sixb.Median4(len(ar[first]), len(ar[first+step]),
 		len(ar[first+2*step]), len(ar[first+3*step]))
 
-	k := partOneHL(ar, pv)
+	k, _ := partOneHL(ar, pv)
 	var aq S
 
 	if k < len(ar)-k {
@@ -241,14 +334,14 @@ start:
 		ar = ar[:k:k]
 	}
 
-	if len(aq) > MaxLenIns {
-		shortHL(aq) // recurse on the shorter range
+	if len(aq) > lm.ins {
+		shortHL(aq, lm) // recurse on the shorter range
 		goto start
 	}
 isort:
 	insertionHL(aq) // at least one insertion range
 
-	if len(ar) > MaxLenIns {
+	if len(ar) > lm.ins {
 		goto start
 	}
 	if &ar[0] != &aq[0] {
@@ -260,21 +353,56 @@ isort:
 // new-goroutine sort function
 //
 //go:nosplit
-func gLongHL[S ~[]T, T hasLen](ar S, sv *syncVar) {
-	longHL(ar, sv)
+func gLongHL[S ~[]T, T hasLen](ar S, lm limits, sv *syncVar) {
+	longHL(ar, lm, sv)
 
-	if atomic.AddUint64(&sv.nGor, ^uint64(0)) == 0 { // decrease goroutine counter
+	if subGor(sv) == 0 { // decrease goroutine counters
 		sv.done <- 0 // we are the last, all done
 	}
 }
 
-// long range sort function, assumes len(ar) > MaxLenRec, recursive
-func longHL[S ~[]T, T hasLen](ar S, sv *syncVar) {
+// long range sort function, assumes len(ar) > lm.rec, recursive
+func longHL[S ~[]T, T hasLen](ar S, lm limits, sv *syncVar) {
 start:
-	pv := pivotHL(ar, nsLong) // median-of-n pivot
-	k := partOneHL(ar, pv)
+	if stopped(sv) {
+		return
+	}
+	if lm.dep--; lm.dep < 0 { // too deep, avoid quadratic time
+		heapC(ar, cmpLen[T])
+		return
+	}
+	if presortedHL(ar) {
+		return
+	}
+	a, b := pivotHL(ar, nsLong) // median-of-n pivot
 	var aq S
 
+	if a == b { // many members equal to pivot? exclude them from further sorting
+		l, h := partThreeHL(ar, a)
+		aq, ar = ar[:l:l], ar[h:]
+		if len(aq) > len(ar) {
+			aq, ar = ar, aq // ar is the longer range
+		}
+		if len(aq) > lm.rec {
+			longHL(aq, lm, sv)
+		} else if len(aq) > lm.ins {
+			shortHL(aq, lm)
+		} else {
+			insertionHL(aq)
+		}
+		if len(ar) > lm.rec {
+			goto start
+		}
+		if len(ar) > lm.ins {
+			shortHL(ar, lm)
+		} else {
+			insertionHL(ar)
+		}
+		return
+	}
+
+	k, sw := partOneHL(ar, sixb.Mean(a, b))
+
 	if k < len(ar)-k {
 		aq = ar[:k:k]
 		ar = ar[k:] // ar is the longer range
@@ -283,32 +411,36 @@ start:
 		ar = ar[:k:k]
 	}
 
+	// balanced partition without swaps, nearly sorted input?
+	if !sw && len(aq) > len(ar)>>3 && partInsertionHL(aq) && partInsertionHL(ar) {
+		return
+	}
+
 	// branches below are optimal for fewer total jumps
-	if len(aq) <= MaxLenRec { // at least one not-long range?
+	if len(aq) <= lm.rec { // at least one not-long range?
 
-		if len(aq) > MaxLenIns {
-			shortHL(aq)
+		if len(aq) > lm.ins {
+			shortHL(aq, lm)
 		} else {
 			insertionHL(aq)
 		}
 
-		if len(ar) > MaxLenRec { // two not-long ranges?
+		if len(ar) > lm.rec { // two not-long ranges?
 			goto start
 		}
-		shortHL(ar) // we know len(ar) > MaxLenIns
+		shortHL(ar, lm) // we know len(ar) > lm.ins
 		return
 	}
 
-	// max goroutines? not atomic but good enough
-	if sv == nil || gorFull(sv) {
-		longHL(aq, sv) // recurse on the shorter range
+	// max goroutines? claim a new goroutine otherwise
+	if !claim(sv) {
+		longHL(aq, lm, sv) // recurse on the shorter range
 		goto start
 	}
 
 	// new-goroutine sort on the longer range only when
 	// both ranges are big and max goroutines is not exceeded
-	atomic.AddUint64(&sv.nGor, 1) // increase goroutine counter
-	go gLongHL(ar, sv)
+	spawn3(sv.wp, gLongHL, ar, lm, sv)
 	ar = aq
 	goto start
 }
@@ -316,26 +448,30 @@ start:
 // sortHL concurrently sorts ar by length in ascending order.
 //
 //go:nosplit
-func sortHL[S ~[]T, T hasLen](ar S) {
+func sortHL[S ~[]T, T hasLen](ar S, lm limits, mg *uint64, stop *uint32, wp *Pool) {
+	lm.dep = maxDepth(len(ar))
 
-	if len(ar) < 2*(MaxLenRec+1) || MaxGor <= 1 {
+	if len(ar) < 2*(lm.rec+1) || *mg <= 1 || globalFull() {
 
-		if len(ar) > MaxLenRec { // single-goroutine sorting
-			longHL(ar, nil)
-		} else if len(ar) > MaxLenIns {
-			shortHL(ar)
+		if len(ar) > lm.rec { // single-goroutine sorting
+			longHL(ar, lm, serialVar(mg, stop))
+		} else if len(ar) > lm.ins {
+			shortHL(ar, lm)
 		} else {
 			insertionHL(ar)
 		}
 		return
 	}
 
+	if presortedHL(ar) { // nothing to partition
+		return
+	}
 	// create channel only when concurrent partitioning & sorting
-	sv := syncVar{1, // number of goroutines including this
-		make(chan int)} // end signal
+	sv := newSyncVar(mg, stop, wp)
 	for {
+		lm.dep--
 		// concurrent dual partitioning with done
-		k := partConHL(ar, sv.done)
+		k, sw := partConHL(ar, sv)
 		var aq S
 
 		if k < len(ar)-k {
@@ -346,27 +482,35 @@ func sortHL[S ~[]T, T hasLen](ar S) {
 			ar = ar[:k:k]
 		}
 
+		// balanced partition without swaps, nearly sorted input?
+		if !sw && len(aq) > len(ar)>>3 && partInsertionHL(aq) && partInsertionHL(ar) {
+			goto wait // longer range is sorted too
+		}
+
 		// handle shorter range
-		if len(aq) > MaxLenRec {
-			atomic.AddUint64(&sv.nGor, 1) // increase goroutine counter
-			go gLongHL(aq, &sv)
+		if len(aq) > lm.rec && claim(sv) {
+			spawn3(wp, gLongHL, aq, lm, sv)
 
-		} else if len(aq) > MaxLenIns {
-			shortHL(aq)
+		} else if len(aq) > lm.rec {
+			longHL(aq, lm, sv) // goroutine quota is full
+		} else if len(aq) > lm.ins {
+			shortHL(aq, lm)
 		} else {
 			insertionHL(aq)
 		}
 
 		// longer range big enough? max goroutines?
-		if len(ar) < 2*(MaxLenRec+1) || gorFull(&sv) {
+		if len(ar) < 2*(lm.rec+1) || lm.dep <= 0 || gorFull(sv) || stopped(sv) {
 			break
 		}
 		// dual partition longer range
 	}
 
-	longHL(ar, &sv) // we know len(ar) > MaxLenRec
+	longHL(ar, lm, sv) // we know len(ar) > lm.rec
 
+wait:
 	if atomic.AddUint64(&sv.nGor, ^uint64(0)) != 0 { // decrease goroutine counter
 		<-sv.done // we are not the last, wait
 	}
+	release(sv)
 }
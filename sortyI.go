@@ -22,13 +22,20 @@ func pivotI[S ~[]T, T sb.Integer](slc S, n uint) T {
 	return sb.Mean(a, b)
 }
 
-// short range sort function, assumes MaxLenIns < len(ar) <= MaxLenRec, recursive
-func shortI[S ~[]T, T sb.Integer](ar S) {
+// short range sort function, assumes lm.ins < len(ar) <= lm.rec, recursive
+func shortI[S ~[]T, T sb.Integer](ar S, lm limits) {
 start:
+	if lm.dep--; lm.dep < 0 { // too deep, avoid quadratic time
+		heapO(ar)
+		return
+	}
+	if presortedO(ar) {
+		return
+	}
 	first, step := minMaxFour(uint32(len(ar)))
 	pv := sb.Median4(ar[first], ar[first+step], ar[first+2*step], ar[first+3*step])
 
-	k := partOneO(ar, pv)
+	k, _ := partOneO(ar, pv)
 	var aq S
 
 	if k < len(ar)-k {
@@ -39,14 +46,14 @@ start:
 		ar = ar[:k:k]
 	}
 
-	if len(aq) > MaxLenIns {
-		shortI(aq) // recurse on the shorter range
+	if len(aq) > lm.ins {
+		shortI(aq, lm) // recurse on the shorter range
 		goto start
 	}
 isort:
 	insertionO(aq) // at least one insertion range
 
-	if len(ar) > MaxLenIns {
+	if len(ar) > lm.ins {
 		goto start
 	}
 	if &ar[0] != &aq[0] {
@@ -58,21 +65,56 @@ isort:
 // new-goroutine sort function
 //
 //go:nosplit
-func gLongI[S ~[]T, T sb.Integer](ar S, sv *syncVar) {
-	longI(ar, sv)
+func gLongI[S ~[]T, T sb.Integer](ar S, lm limits, sv *syncVar) {
+	longI(ar, lm, sv)
 
-	if atomic.AddUint64(&sv.nGor, ^uint64(0)) == 0 { // decrease goroutine counter
+	if subGor(sv) == 0 { // decrease goroutine counters
 		sv.done <- 0 // we are the last, all done
 	}
 }
 
-// long range sort function, assumes len(ar) > MaxLenRec, recursive
-func longI[S ~[]T, T sb.Integer](ar S, sv *syncVar) {
+// long range sort function, assumes len(ar) > lm.rec, recursive
+func longI[S ~[]T, T sb.Integer](ar S, lm limits, sv *syncVar) {
 start:
-	pv := pivotI(ar, nsLong) // median-of-n pivot
-	k := partOneO(ar, pv)
+	if stopped(sv) {
+		return
+	}
+	if lm.dep--; lm.dep < 0 { // too deep, avoid quadratic time
+		heapO(ar)
+		return
+	}
+	if presortedO(ar) {
+		return
+	}
+	a, b := pivotO(ar, nsLong) // median-of-n pivot
 	var aq S
 
+	if a == b { // many members equal to pivot? exclude them from further sorting
+		l, h := partThreeO(ar, a)
+		aq, ar = ar[:l:l], ar[h:]
+		if len(aq) > len(ar) {
+			aq, ar = ar, aq // ar is the longer range
+		}
+		if len(aq) > lm.rec {
+			longI(aq, lm, sv)
+		} else if len(aq) > lm.ins {
+			shortI(aq, lm)
+		} else {
+			insertionO(aq)
+		}
+		if len(ar) > lm.rec {
+			goto start
+		}
+		if len(ar) > lm.ins {
+			shortI(ar, lm)
+		} else {
+			insertionO(ar)
+		}
+		return
+	}
+
+	k, sw := partOneO(ar, sb.Mean(a, b))
+
 	if k < len(ar)-k {
 		aq = ar[:k:k]
 		ar = ar[k:] // ar is the longer range
@@ -81,60 +123,88 @@ start:
 		ar = ar[:k:k]
 	}
 
+	// balanced partition without swaps, nearly sorted input?
+	if !sw && len(aq) > len(ar)>>3 && partInsertionO(aq) && partInsertionO(ar) {
+		return
+	}
+
 	// branches below are optimal for fewer total jumps
-	if len(aq) <= MaxLenRec { // at least one not-long range?
+	if len(aq) <= lm.rec { // at least one not-long range?
 
-		if len(aq) > MaxLenIns {
-			shortI(aq)
+		if len(aq) > lm.ins {
+			shortI(aq, lm)
 		} else {
 			insertionO(aq)
 		}
 
-		if len(ar) > MaxLenRec { // two not-long ranges?
+		if len(ar) > lm.rec { // two not-long ranges?
 			goto start
 		}
-		shortI(ar) // we know len(ar) > MaxLenIns
+		shortI(ar, lm) // we know len(ar) > lm.ins
 		return
 	}
 
-	// max goroutines? not atomic but good enough
-	if sv == nil || gorFull(sv) {
-		longI(aq, sv) // recurse on the shorter range
+	// max goroutines? claim a new goroutine otherwise
+	if !claim(sv) {
+		longI(aq, lm, sv) // recurse on the shorter range
 		goto start
 	}
 
 	// new-goroutine sort on the longer range only when
 	// both ranges are big and max goroutines is not exceeded
-	atomic.AddUint64(&sv.nGor, 1) // increase goroutine counter
-	go gLongI(ar, sv)
+	spawn3(sv.wp, gLongI, ar, lm, sv)
 	ar = aq
 	goto start
 }
 
-// sortI concurrently sorts ar in ascending order.
+// sortI concurrently sorts ar in ascending order. It counting sorts long ar with a
+// small range of values, otherwise continues with sortWideI.
+func sortI[S ~[]T, T sb.Integer](ar, buf S, lm limits, mg *uint64, stop *uint32,
+	wp *Pool) {
+	if len(ar) > lm.rec && uint64(len(ar)) < 1<<32 { // small range of values?
+		if lo, span := spanI(ar); span > 0 {
+			countI(ar, lo, span, mg, stop, wp)
+			return
+		}
+	}
+	sortWideI(ar, buf, lm, mg, stop, wp)
+}
+
+// sortWideI concurrently sorts ar in ascending order without checking its range of
+// values, as for distinct indices. It radix sorts ar if it is long enough and buf can
+// hold it.
 //
 //go:nosplit
-func sortI[S ~[]T, T sb.Integer](ar S) {
+func sortWideI[S ~[]T, T sb.Integer](ar, buf S, lm limits, mg *uint64, stop *uint32,
+	wp *Pool) {
+	if len(ar) >= MinLenRadix && len(buf) >= len(ar) {
+		radixI(ar, buf, mg, stop, wp)
+		return
+	}
+	lm.dep = maxDepth(len(ar))
 
-	if len(ar) < 2*(MaxLenRec+1) || MaxGor <= 1 {
+	if len(ar) < 2*(lm.rec+1) || *mg <= 1 || globalFull() {
 
-		if len(ar) > MaxLenRec { // single-goroutine sorting
-			longI(ar, nil)
-		} else if len(ar) > MaxLenIns {
-			shortI(ar)
+		if len(ar) > lm.rec { // single-goroutine sorting
+			longI(ar, lm, serialVar(mg, stop))
+		} else if len(ar) > lm.ins {
+			shortI(ar, lm)
 		} else {
 			insertionO(ar)
 		}
 		return
 	}
 
+	if presortedO(ar) { // nothing to partition
+		return
+	}
 	// create channel only when concurrent partitioning & sorting
-	sv := syncVar{1, // number of goroutines including this
-		make(chan int)} // end signal
+	sv := newSyncVar(mg, stop, wp)
 	for {
+		lm.dep--
 		// concurrent dual partitioning with done
 		pv := pivotI(ar, nsConc) // median-of-n pivot
-		k := partConO(ar, pv, sv.done)
+		k, sw := partConO(ar, pv, sv)
 		var aq S
 
 		if k < len(ar)-k {
@@ -145,27 +215,35 @@ func sortI[S ~[]T, T sb.Integer](ar S) {
 			ar = ar[:k:k]
 		}
 
+		// balanced partition without swaps, nearly sorted input?
+		if !sw && len(aq) > len(ar)>>3 && partInsertionO(aq) && partInsertionO(ar) {
+			goto wait // longer range is sorted too
+		}
+
 		// handle shorter range
-		if len(aq) > MaxLenRec {
-			atomic.AddUint64(&sv.nGor, 1) // increase goroutine counter
-			go gLongI(aq, &sv)
+		if len(aq) > lm.rec && claim(sv) {
+			spawn3(wp, gLongI, aq, lm, sv)
 
-		} else if len(aq) > MaxLenIns {
-			shortI(aq)
+		} else if len(aq) > lm.rec {
+			longI(aq, lm, sv) // goroutine quota is full
+		} else if len(aq) > lm.ins {
+			shortI(aq, lm)
 		} else {
 			insertionO(aq)
 		}
 
 		// longer range big enough? max goroutines?
-		if len(ar) < 2*(MaxLenRec+1) || gorFull(&sv) {
+		if len(ar) < 2*(lm.rec+1) || lm.dep <= 0 || gorFull(sv) || stopped(sv) {
 			break
 		}
 		// dual partition longer range
 	}
 
-	longI(ar, &sv) // we know len(ar) > MaxLenRec
+	longI(ar, lm, sv) // we know len(ar) > lm.rec
 
+wait:
 	if atomic.AddUint64(&sv.nGor, ^uint64(0)) != 0 { // decrease goroutine counter
 		<-sv.done // we are not the last, wait
 	}
+	release(sv)
 }
@@ -7,11 +7,35 @@
 package sorty
 
 import (
+	"context"
 	"reflect"
+	"slices"
 
 	"github.com/jfcg/sixb/v2"
 )
 
+// isSortedLen returns 0 if ar is sorted 'by length' in ascending/descending order,
+// otherwise it returns i > 0 with len(ar[i]) < len(ar[i-1]) (or > for descending).
+// Returns -1 for invalid input types.
+//
+//go:nosplit
+func isSortedLen(ar any, cf *config) int {
+	slc, kind := extractSK(ar)
+	switch {
+	case kind == reflect.String:
+		if cf.desc {
+			return isSortedDescHL(sixb.Cast[string](slc))
+		}
+		return isSortedHL(sixb.Cast[string](slc))
+	case kind >= sliceBias:
+		if cf.desc {
+			return isSortedDescHL(sixb.Cast[[]struct{}](slc))
+		}
+		return isSortedHL(sixb.Cast[[]struct{}](slc))
+	}
+	return -1
+}
+
 // IsSortedLen returns 0 if ar is sorted 'by length' in ascending order, otherwise
 // it returns i > 0 with len(ar[i]) < len(ar[i-1]). ar's (underlying) type can be
 //
@@ -21,14 +45,53 @@ import (
 //
 //go:nosplit
 func IsSortedLen(ar any) int {
+	cf := defConfig()
+	i := isSortedLen(ar, &cf)
+	if i < 0 {
+		panic("sorty: IsSortedLen: invalid input type")
+	}
+	return i
+}
+
+// IsSortedLenDesc returns 0 if ar is sorted 'by length' in descending order, otherwise
+// it returns i > 0 with len(ar[i]) > len(ar[i-1]). ar's (underlying) type can be
+//
+//	[]string, [][]T // for any type T
+//
+// otherwise it panics.
+//
+//go:nosplit
+func IsSortedLenDesc(ar any) int {
+	cf := defConfig()
+	cf.desc = true
+	i := isSortedLen(ar, &cf)
+	if i < 0 {
+		panic("sorty: IsSortedLenDesc: invalid input type")
+	}
+	return i
+}
+
+// sortLen concurrently sorts ar 'by length' in ascending/descending order.
+// Returns false for invalid input types.
+func sortLen(ar any, cf *config) bool {
 	slc, kind := extractSK(ar)
 	switch {
 	case kind == reflect.String:
-		return isSortedHL(sixb.Cast[string](slc))
+		ss := sixb.Cast[string](slc)
+		sortHL(ss, cf.lm, cf.mg, cf.stop, cf.wp)
+		if cf.desc { // reverse ascending result like sortSlice
+			slices.Reverse(ss)
+		}
 	case kind >= sliceBias:
-		return isSortedHL(sixb.Cast[[]struct{}](slc))
+		ss := sixb.Cast[[]struct{}](slc)
+		sortHL(ss, cf.lm, cf.mg, cf.stop, cf.wp)
+		if cf.desc {
+			slices.Reverse(ss)
+		}
+	default:
+		return false
 	}
-	panic("sorty: IsSortedLen: invalid input type")
+	return true
 }
 
 // SortLen concurrently sorts ar 'by length' in ascending order. ar's (underlying)
@@ -40,13 +103,78 @@ func IsSortedLen(ar any) int {
 //
 //go:nosplit
 func SortLen(ar any) {
+	cf := defConfig()
+	if !sortLen(ar, &cf) {
+		panic("sorty: SortLen: invalid input type")
+	}
+}
+
+// SortLenCtx is like [SortLen] but stops promptly when ctx is done before sorting
+// finishes, in which case it returns ctx.Err() and ar is left as a permutation of its
+// original members.
+func SortLenCtx(ctx context.Context, ar any) error {
+	cf := defConfig()
+	done := cancelOn(ctx, &cf)
+	ok := sortLen(ar, &cf)
+	err := done()
+	if !ok {
+		panic("sorty: SortLenCtx: invalid input type")
+	}
+	return err
+}
+
+// SortLenDesc concurrently sorts ar 'by length' in descending order. ar's (underlying)
+// type can be
+//
+//	[]string, [][]T // for any type T
+//
+// otherwise it panics. ar is sorted in ascending order like [SortLen] and then reversed
+// in one extra O(n) pass, like [SortSliceDesc].
+//
+//go:nosplit
+func SortLenDesc(ar any) {
+	cf := defConfig()
+	cf.desc = true
+	if !sortLen(ar, &cf) {
+		panic("sorty: SortLenDesc: invalid input type")
+	}
+}
+
+// sortLenStable concurrently and stably sorts ar 'by length' in ascending/descending
+// order, using buf as scratch space if possible. Returns false for invalid input types.
+func sortLenStable(ar, buf any, cf *config) bool {
 	slc, kind := extractSK(ar)
+	bs, n := scratchOf(ar, buf), int(slc.Len)
 	switch {
 	case kind == reflect.String:
-		sortHL(sixb.Cast[string](slc))
+		cmp := cmpLen[string]
+		if cf.desc {
+			cmp = reverseCmp(cmp)
+		}
+		sortStableC(sixb.Cast[string](slc), scratch[string](n, bs), cmp, cf.fc, cf.mg, cf.wp)
 	case kind >= sliceBias:
-		sortHL(sixb.Cast[[]struct{}](slc))
+		cmp := cmpLen[[]struct{}]
+		if cf.desc {
+			cmp = reverseCmp(cmp)
+		}
+		sortStableC(sixb.Cast[[]struct{}](slc), scratch[[]struct{}](n, bs), cmp, cf.fc, cf.mg,
+			cf.wp)
 	default:
-		panic("sorty: SortLen: invalid input type")
+		return false
+	}
+	return true
+}
+
+// SortLenStable concurrently sorts ar 'by length' in ascending order, keeping relative
+// order of members with equal lengths. ar's (underlying) type can be
+//
+//	[]string, [][]T // for any type T
+//
+// otherwise it panics. buf is optional scratch space with the same type as ar and
+// length ≥ (len(ar)+1)/2, otherwise (for example nil) a scratch space is allocated.
+func SortLenStable(ar, buf any) {
+	cf := defConfig()
+	if !sortLenStable(ar, buf, &cf) {
+		panic("sorty: SortLenStable: invalid input type")
 	}
 }
@@ -7,7 +7,7 @@
 package sorty
 
 import (
-	"sync/atomic"
+	"context"
 
 	"github.com/jfcg/sixb/v2"
 )
@@ -50,6 +50,68 @@ func insertion(lsw Lesswap, lo, hi int) {
 	}
 }
 
+// presorted returns true if ar[lo..hi] is sorted, after reversing it if it is sorted
+// in descending order. It returns at the first out-of-order pair from the end.
+func presorted(lsw Lesswap, lo, hi int) bool {
+	k := hi
+	for k > lo && !lsw(k, k-1, k, k) { // 3rd=4th disables swap
+		k--
+	}
+	if k == lo {
+		return true
+	}
+	for k = hi; k > lo; k-- {
+		if lsw(k-1, k, k, k) {
+			return false
+		}
+	}
+	for ; lo < hi; lo, hi = lo+1, hi-1 {
+		lsw(hi, lo, hi, lo) // no swap for equal members
+	}
+	return true
+}
+
+// partial insertion sort ar[lo..hi], gives up when members move by a total distance
+// more than maxShift. Returns true if ar[lo..hi] is sorted.
+func partInsertion(lsw Lesswap, lo, hi int) bool {
+	shift := 0
+	for h := lo + 1; h <= hi; h++ {
+		l := h
+		for l > lo && lsw(l, l-1, l, l-1) {
+			l--
+		}
+		if shift += h - l; shift > maxShift {
+			return false
+		}
+	}
+	return true
+}
+
+// sift moves member lo+i down the max-heap of n members starting at lo
+func sift(lsw Lesswap, lo, i, n int) {
+	for c := 2*i + 1; c < n; c = 2*i + 1 {
+		if c+1 < n && lsw(lo+c, lo+c+1, lo, lo) {
+			c++
+		}
+		if !lsw(lo+i, lo+c, lo+i, lo+c) {
+			return
+		}
+		i = c
+	}
+}
+
+// heapsort ar[lo..hi], used when partitioning gets too deep
+func heapsort(lsw Lesswap, lo, hi int) {
+	n := hi + 1 - lo
+	for i := n>>1 - 1; i >= 0; i-- {
+		sift(lsw, lo, i, n)
+	}
+	for n--; n > 0; n-- {
+		lsw(lo+n, lo, lo+n, lo) // move max to the end, no-op for equal members
+		sift(lsw, lo, 0, n)
+	}
+}
+
 // pivot selects n equidistant samples from slc[lo:hi+1] that minimizes max distance
 // to non-selected members, then calculates median-of-n pivot from samples.
 // Assumes odd n ≥ 3 and len(slc) ≥ 2n. Returns pivot position.
@@ -80,12 +142,13 @@ func pivot(lsw Lesswap, lo, hi int, n uint) int {
 	return sixb.Mean(first, last)
 }
 
-// partition slc, returns k with slc[:k] ≤ pivot ≤ slc[k:]
+// partition slc, returns k with slc[:k] ≤ pivot ≤ slc[k:] & whether it swapped
 // swap: slc[h] < pv < slc[l]
 // next: slc[l] ≤ pv ≤ slc[h]
 //
 //go:nosplit
-func partOne(lsw Lesswap, l, pv, h int) int {
+func partOne(lsw Lesswap, l, pv, h int) (int, bool) {
+	sw := false
 	// avoid unnecessary comparisons, extend ranges in balance
 	for ; l < h; l, h = l+1, h-1 {
 
@@ -96,35 +159,61 @@ func partOne(lsw Lesswap, l, pv, h int) int {
 				}
 				l++
 				if l >= h {
-					return l + 1
+					return l + 1, sw
 				}
 			}
+			sw = true
 		} else if lsw(pv, l, l, l) { // 3rd=4th disables swap
 			for {
 				h--
 				if l >= h {
-					return l
+					return l, sw
 				}
 				if lsw(h, pv, h, l) {
 					break
 				}
 			}
+			sw = true
 		}
 	}
 	// classify mid element
 	if l == h && h != pv && lsw(h, pv, h, h) { // 3rd=4th disables swap
 		l++
 	}
-	return l
+	return l, sw
+}
+
+// three-way partition slc[lo..hi], returns l, h with
+// slc[lo..l-1] < pivot = slc[l..h] < slc[h+1..hi]. Pivot position pv can change.
+func partThree(lsw Lesswap, lo, pv, hi int) (int, int) {
+	for i := lo; i <= hi; {
+		if lsw(i, pv, lo, i) { // slc[i] < pivot
+			if pv == lo {
+				pv = i // pivot moved
+			}
+			lo++
+			i++
+		} else if lsw(pv, i, i, hi) { // pivot < slc[i]
+			if pv == hi {
+				pv = i // pivot moved
+			}
+			hi--
+		} else {
+			i++
+		}
+	}
+	return lo, hi
 }
 
 // swaps elements to get slc[lo..l] ≤ pivot ≤ slc[h..hi]
-// Gap (l,h) expands until one of the intervals is fully consumed.
+// Gap (l,h) expands until one of the intervals is fully consumed. Also returns
+// whether it swapped
 // swap: slc[h] < pv < slc[l]
 // next: slc[l] ≤ pv ≤ slc[h]
 //
 //go:nosplit
-func partTwo(lsw Lesswap, lo, l, pv, h, hi int) int {
+func partTwo(lsw Lesswap, lo, l, pv, h, hi int) (int, bool) {
+	sw := false
 	// avoid unnecessary comparisons, extend ranges in balance
 	for {
 		if lsw(h, pv, h, h) { // 3rd=4th disables swap
@@ -134,59 +223,83 @@ func partTwo(lsw Lesswap, lo, l, pv, h, hi int) int {
 				}
 				l--
 				if l < lo {
-					return h
+					return h, sw
 				}
 			}
+			sw = true
 		} else if lsw(pv, l, l, l) { // 3rd=4th disables swap
 			for {
 				h++
 				if h > hi {
-					return l
+					return l, sw
 				}
 				if lsw(h, pv, h, l) {
 					break
 				}
 			}
+			sw = true
 		}
 		l--
 		h++
 		if l < lo {
-			return h
+			return h, sw
 		}
 		if h > hi {
-			return l
+			return l, sw
 		}
 	}
 }
 
-// new-goroutine partition
-//
-//go:nosplit
-func gPartOne(lsw Lesswap, l, pv, h int, ch chan int) {
-	ch <- partOne(lsw, l, pv, h)
+// new-goroutine partition, sends -1 if lsw() panics
+func gPartOne(lsw Lesswap, l, pv, h int, sv *syncVar) {
+	k := -1
+	defer func() {
+		if p := recover(); p != nil {
+			record(sv, p)
+		}
+		unreserve(sv)
+		sv.done <- k
+	}()
+	k = packSw(partOne(lsw, l, pv, h))
 }
 
-// partition slc in two goroutines, returns k with slc[:k] ≤ pivot ≤ slc[k:]
-//
-//go:nosplit
-func partCon(lsw Lesswap, lo, hi int, ch chan int) int {
+// partition slc in two goroutines if budget allows, returns k with
+// slc[:k] ≤ pivot ≤ slc[k:] & whether it swapped, or -1 if lsw() panicked in the
+// new goroutine
+func partCon(lsw Lesswap, lo, hi int, sv *syncVar) (int, bool) {
 
 	pv := pivot(lsw, lo, hi, nsConc-1) // median-of-n pivot
 	lo++
 	hi--
 	l, h := sixb.Mean(lo, pv), sixb.Mean(pv, hi)
 
-	go gPartOne(lsw, l+1, pv, h-1, ch) // mid half range
-
-	r := partTwo(lsw, lo, l, pv, h, hi) // left/right quarter ranges
+	gor := reserve(sv, 1) != 0 // budget for a new goroutine?
+	if gor {
+		spawn5(sv.wp, gPartOne, lsw, l+1, pv, h-1, sv) // mid half range
+	}
 
-	k := <-ch
+	k := -2 // not received yet
+	defer func() {
+		if k == -2 && gor { // partTwo() panicked, wait for gPartOne()
+			<-sv.done
+		}
+	}()
+	r, sw := partTwo(lsw, lo, l, pv, h, hi) // left/right quarter ranges
+
+	sm := false
+	if !gor {
+		k, sm = partOne(lsw, l+1, pv, h-1) // mid half range
+	} else if k, sm = unpackSw(<-sv.done); k < 0 {
+		return k, sw
+	}
+	sw = sw || sm
 
 	// only one gap is possible
 	if r < pv {
 		for ; lo <= r; r-- { // gap left in low range?
 			if lsw(pv, r, k-1, r) {
 				k--
+				sw = true
 				if k == pv { // swapped pivot when closing gap?
 					pv = r // Thanks to my wife Tansu who discovered this
 				}
@@ -199,15 +312,23 @@ func partCon(lsw Lesswap, lo, hi int, ch chan int) int {
 					pv = r // It took days of agony to discover these two if's :D
 				}
 				k++
+				sw = true
 			}
 		}
 	}
-	return k
+	return k, sw
 }
 
-// short range sort function, assumes MaxLenInsFC <= hi-lo < MaxLenRecFC, recursive
-func short(lsw Lesswap, lo, hi int) {
+// short range sort function, assumes lm.ins <= hi-lo < lm.rec, recursive
+func short(lsw Lesswap, lo, hi int, lm limits) {
 start:
+	if lm.dep--; lm.dep < 0 { // too deep, avoid quadratic time
+		heapsort(lsw, lo, hi)
+		return
+	}
+	if presorted(lsw, lo, hi) {
+		return
+	}
 	fr, step, _ := minMaxSample(uint(hi+1-lo), 3)
 	first := lo + int(fr)
 	pv := first + int(step)
@@ -222,7 +343,7 @@ start:
 	lsw(first, lo, first, lo)
 	lsw(hi, last, hi, last)
 
-	l := partOne(lsw, lo+1, pv, hi-1)
+	l, _ := partOne(lsw, lo+1, pv, hi-1)
 	h := l - 1
 	no, n := h-lo, hi-l
 
@@ -233,8 +354,8 @@ start:
 		h, hi = hi, h
 	}
 
-	if n >= MaxLenInsFC {
-		short(lsw, l, h) // recurse on the shorter range
+	if n >= lm.ins {
+		short(lsw, l, h, lm) // recurse on the shorter range
 		goto start
 	}
 	// at least one insertion range, insertion inlined
@@ -248,7 +369,7 @@ isort:
 		}
 	}
 
-	if no >= MaxLenInsFC {
+	if no >= lm.ins {
 		goto start
 	}
 	if lo != l {
@@ -260,19 +381,54 @@ isort:
 // new-goroutine sort function
 //
 //go:nosplit
-func gLong(lsw Lesswap, lo, hi int, sv *syncVar) {
-	long(lsw, lo, hi, sv)
-
-	if atomic.AddUint64(&sv.nGor, ^uint64(0)) == 0 { // decrease goroutine counter
-		sv.done <- 0 // we are the last, all done
-	}
+func gLong(lsw Lesswap, lo, hi int, lm limits, sv *syncVar) {
+	defer finish(sv)
+	long(lsw, lo, hi, lm, sv)
 }
 
-// long range sort function, assumes hi-lo >= MaxLenRecFC, recursive
-func long(lsw Lesswap, lo, hi int, sv *syncVar) {
+// long range sort function, assumes hi-lo >= lm.rec, recursive
+func long(lsw Lesswap, lo, hi int, lm limits, sv *syncVar) {
 start:
+	if stopped(sv) {
+		return
+	}
+	if lm.dep--; lm.dep < 0 { // too deep, avoid quadratic time
+		heapsort(lsw, lo, hi)
+		return
+	}
+	if presorted(lsw, lo, hi) {
+		return
+	}
 	pv := pivot(lsw, lo, hi, nsLong-1) // median-of-n pivot
-	l := partOne(lsw, lo+1, pv, hi-1)
+
+	// median sample equals its predecessor? many members equal to pivot?
+	if _, s, _ := minMaxSample(uint(hi+1-lo), nsLong-1); !lsw(pv-int(s), pv, pv, pv) {
+		l, h := partThree(lsw, lo, pv, hi) // exclude them from further sorting
+
+		if l-lo < hi-h {
+			l, h, lo = lo, l-1, h+1 // [lo,hi] is the longer range
+		} else {
+			l, h, hi = h+1, hi, l-1
+		}
+		if h-l >= lm.rec {
+			long(lsw, l, h, lm, sv)
+		} else if h-l >= lm.ins {
+			short(lsw, l, h, lm)
+		} else {
+			insertion(lsw, l, h)
+		}
+		if hi-lo >= lm.rec {
+			goto start
+		}
+		if hi-lo >= lm.ins {
+			short(lsw, lo, hi, lm)
+		} else {
+			insertion(lsw, lo, hi)
+		}
+		return
+	}
+
+	l, sw := partOne(lsw, lo+1, pv, hi-1)
 	h := l - 1
 	no, n := h-lo, hi-l
 
@@ -283,32 +439,36 @@ start:
 		h, hi = hi, h
 	}
 
+	// balanced partition without swaps, nearly sorted input?
+	if !sw && n > no>>3 && partInsertion(lsw, l, h) && partInsertion(lsw, lo, hi) {
+		return
+	}
+
 	// branches below are optimal for fewer total jumps
-	if n < MaxLenRecFC { // at least one not-long range?
+	if n < lm.rec { // at least one not-long range?
 
-		if n >= MaxLenInsFC {
-			short(lsw, l, h)
+		if n >= lm.ins {
+			short(lsw, l, h, lm)
 		} else {
 			insertion(lsw, l, h)
 		}
 
-		if no >= MaxLenRecFC { // two not-long ranges?
+		if no >= lm.rec { // two not-long ranges?
 			goto start
 		}
-		short(lsw, lo, hi) // we know no >= MaxLenInsFC
+		short(lsw, lo, hi, lm) // we know no >= lm.ins
 		return
 	}
 
-	// max goroutines? not atomic but good enough
-	if sv == nil || gorFull(sv) {
-		long(lsw, l, h, sv) // recurse on the shorter range
+	// max goroutines? claim a new goroutine otherwise
+	if !claim(sv) {
+		long(lsw, l, h, lm, sv) // recurse on the shorter range
 		goto start
 	}
 
 	// new-goroutine sort on the longer range only when
 	// both ranges are big and max goroutines is not exceeded
-	atomic.AddUint64(&sv.nGor, 1) // increase goroutine counter
-	go gLong(lsw, lo, hi, sv)
+	spawn5(sv.wp, gLong, lsw, lo, hi, lm, sv)
 	lo, hi = l, h
 	goto start
 }
@@ -335,27 +495,54 @@ start:
 //
 //go:nosplit
 func Sort(n int, lsw Lesswap) {
+	sortLesswap(n, lsw, limits{MaxLenInsFC, MaxLenRecFC, 0}, &MaxGor, nil, nil)
+}
 
+// SortCtx is like [Sort] but stops promptly when ctx is done before sorting finishes,
+// in which case it returns ctx.Err() and the underlying collection is left as a
+// permutation of its original members.
+func SortCtx(ctx context.Context, n int, lsw Lesswap) error {
+	cf := defConfig()
+	done := cancelOn(ctx, &cf)
+	sortLesswap(n, lsw, cf.fc, cf.mg, cf.stop, cf.wp)
+	return done()
+}
+
+// sortLesswap concurrently sorts underlying collection of length n via lsw().
+//
+//go:nosplit
+func sortLesswap(n int, lsw Lesswap, lm limits, mg *uint64, stop *uint32, wp *Pool) {
+
+	lm.dep = maxDepth(n)
 	n-- // high index
-	if n <= 2*MaxLenRecFC || MaxGor <= 1 {
+	if n <= 2*lm.rec || *mg <= 1 || globalFull() {
 
-		if n >= MaxLenRecFC { // single-goroutine sorting
-			long(lsw, 0, n, nil)
-		} else if n >= MaxLenInsFC {
-			short(lsw, 0, n)
+		if n >= lm.rec { // single-goroutine sorting
+			long(lsw, 0, n, lm, serialVar(mg, stop))
+		} else if n >= lm.ins {
+			short(lsw, 0, n, lm)
 		} else if n > 0 {
 			insertion(lsw, 0, n)
 		}
 		return
 	}
 
+	if presorted(lsw, 0, n) { // nothing to partition
+		return
+	}
 	// create channel only when concurrent partitioning & sorting
-	sv := syncVar{1, // number of goroutines including this
-		make(chan int)} // end signal
+	sv := newSyncVar(mg, stop, wp)
+	ownStop(sv) // to stop all goroutines when lsw() panics
+	defer collect(sv)
+
 	lo, hi := 0, n
 	for {
+		lm.dep--
 		// concurrent dual partitioning with done
-		l := partCon(lsw, lo, hi, sv.done)
+		l, sw := partCon(lsw, lo, hi, sv)
+		if l < 0 { // lsw() panicked in gPartOne()
+			return
+		}
 		h := l - 1
 		no, n := h-lo, hi-l
 
@@ -366,27 +553,181 @@ func Sort(n int, lsw Lesswap) {
 			h, hi = hi, h
 		}
 
+		// balanced partition without swaps, nearly sorted input?
+		if !sw && n > no>>3 && partInsertion(lsw, l, h) && partInsertion(lsw, lo, hi) {
+			return // longer range is sorted too
+		}
+
 		// handle shorter range
-		if n >= MaxLenRecFC {
-			atomic.AddUint64(&sv.nGor, 1) // increase goroutine counter
-			go gLong(lsw, l, h, &sv)
+		if n >= lm.rec && claim(sv) {
+			spawn5(wp, gLong, lsw, l, h, lm, sv)
 
-		} else if n >= MaxLenInsFC {
-			short(lsw, l, h)
+		} else if n >= lm.rec {
+			long(lsw, l, h, lm, sv) // goroutine quota is full
+		} else if n >= lm.ins {
+			short(lsw, l, h, lm)
 		} else {
 			insertion(lsw, l, h)
 		}
 
 		// longer range big enough? max goroutines?
-		if no <= 2*MaxLenRecFC || gorFull(&sv) {
+		if no <= 2*lm.rec || lm.dep <= 0 || gorFull(sv) || stopped(sv) {
 			break
 		}
 		// dual partition longer range
 	}
 
-	long(lsw, lo, hi, &sv) // we know hi-lo >= MaxLenRecFC
+	long(lsw, lo, hi, lm, sv) // we know hi-lo >= lm.rec
+}
+
+// rotate swaps blocks [a,m) & [m,b) in-place. Assumes a < m < b and all members of
+// [m,b) are less than all members of [a,m), so lsw() can swap on its comparison.
+func rotate(lsw Lesswap, a, m, b int) {
+	i, j := m-a, b-m
+	x, y, n := m-i, m, j
+	if i <= j {
+		y, n = m+j-i, i
+	}
+	for k := 0; k < n; k++ { // swap low & high members
+		lsw(y+k, x+k, x+k, y+k)
+	}
+	if i == j {
+		return
+	}
+
+	// rest of the range has all low or all high members, w is a placed member
+	// of the other kind
+	low, w := i < j, x
+	if low {
+		w, j = y, j-i
+	} else {
+		i -= j
+	}
+	for {
+		x, y, n = m-i, m, j
+		if i <= j {
+			y, n = m+j-i, i
+		}
+		if low {
+			for k := 0; k < n; k++ {
+				lsw(x+k, w, x+k, y+k)
+			}
+		} else {
+			for k := 0; k < n; k++ {
+				lsw(w, x+k, x+k, y+k)
+			}
+		}
+		if i == j {
+			return
+		}
+		if i < j {
+			j -= i
+		} else {
+			i -= j
+		}
+	}
+}
+
+// symMerge stably merges sorted ranges [a,m) & [m,b) in-place, assumes a < m < b.
+// See Pok-Son Kim & Arne Kutzner's SymMerge algorithm, recursive
+func symMerge(lsw Lesswap, a, m, b int) {
+	if m-a == 1 { // insert [a] into [m,b) with binary search
+		l, h := m, b
+		for l < h {
+			c := int(uint(l+h) >> 1)
+			if lsw(c, a, c, c) { // 3rd=4th disables swap
+				l = c + 1
+			} else {
+				h = c
+			}
+		}
+		for k := a; k < l-1; k++ { // [k+1] < [k]
+			lsw(k+1, k, k, k+1)
+		}
+		return
+	}
+	if b-m == 1 { // insert [m] into [a,m) with binary search
+		l, h := a, m
+		for l < h {
+			c := int(uint(l+h) >> 1)
+			if lsw(m, c, m, m) { // 3rd=4th disables swap
+				h = c
+			} else {
+				l = c + 1
+			}
+		}
+		for k := m; k > l; k-- { // [k] < [k-1]
+			lsw(k, k-1, k, k-1)
+		}
+		return
+	}
+
+	mid := int(uint(a+b) >> 1)
+	n := mid + m
+	var start, r int
+	if m > mid {
+		start, r = n-b, mid
+	} else {
+		start, r = a, m
+	}
+	p := n - 1
 
-	if atomic.AddUint64(&sv.nGor, ^uint64(0)) != 0 { // decrease goroutine counter
-		<-sv.done // we are not the last, wait
+	for start < r {
+		c := int(uint(start+r) >> 1)
+		if lsw(p-c, c, c, c) { // 3rd=4th disables swap
+			r = c
+		} else {
+			start = c + 1
+		}
 	}
+
+	end := n - start
+	if start < m && m < end {
+		rotate(lsw, start, m, end) // [m,end) < [start,m)
+	}
+	if a < start && start < mid {
+		symMerge(lsw, a, start, mid)
+	}
+	if mid < end && end < b {
+		symMerge(lsw, mid, end, b)
+	}
+}
+
+// stable stably sorts [lo,hi) in-place, recursive
+func stable(lsw Lesswap, lo, hi, ins int) {
+	if hi-lo <= ins {
+		insertion(lsw, lo, hi-1)
+		return
+	}
+	m := int(uint(lo+hi) >> 1)
+	stable(lsw, lo, m, ins)
+	stable(lsw, m, hi, ins)
+
+	if lsw(m, m-1, m, m) { // 3rd=4th disables swap, not in order?
+		symMerge(lsw, lo, m, hi)
+	}
+}
+
+// SortStable concurrently sorts underlying collection of length n via lsw(),
+// keeping relative order of equal members. Like [Sort], it works in-place with
+// comparisons & swaps only, see [Lesswap].
+func SortStable(n int, lsw Lesswap) {
+	sortStable(n, lsw, limits{MaxLenInsFC, MaxLenRecFC, 0}, &MaxGor, nil)
+}
+
+// sortStable concurrently sorts underlying collection of length n via lsw(),
+// keeping relative order of equal members.
+func sortStable(n int, lsw Lesswap, lm limits, mg *uint64, wp *Pool) {
+	p := stableParts(n, lm, mg)
+	if p < 2 {
+		stable(lsw, 0, n, lm.ins)
+		return
+	}
+	stableCon(n, p, wp, func(lo, hi int) {
+		stable(lsw, lo, hi, lm.ins)
+	}, func(lo, m, hi int) {
+		if lsw(m, m-1, m, m) { // 3rd=4th disables swap, not in order?
+			symMerge(lsw, lo, m, hi)
+		}
+	})
 }
@@ -0,0 +1,242 @@
+/*	Copyright (c) 2026, Serhat Şevki Dinçer.
+	This Source Code Form is subject to the terms of the Mozilla Public
+	License, v. 2.0. If a copy of the MPL was not distributed with this
+	file, You can obtain one at http://mozilla.org/MPL/2.0/.
+*/
+
+package sorty
+
+import (
+	"sync/atomic"
+
+	"github.com/jfcg/sixb/v2"
+)
+
+// Multikey quicksort (MSD radix quicksort) partitions a range on the byte at depth d
+// into <, = and > ranges. Only the = range goes one byte deeper, so common prefixes
+// are scanned once per range instead of once per comparison. Each partition at a
+// depth removes at least one byte value from further partitioning at that depth, so
+// there is no quadratic worst case and no depth limit is needed.
+
+// strLike is the member type of multikey quicksorted slices
+type strLike interface {
+	~string | ~[]byte
+}
+
+// byteAt returns 1 + s[d], or 0 if s has no byte at depth d, inlined
+func byteAt[T strLike](s T, d int) int {
+	if d < len(s) {
+		return int(s[d]) + 1
+	}
+	return 0
+}
+
+// insertion sort on bytes at depth d & on, assumes members share first d bytes
+func insertionM[S ~[]T, T strLike](slc S, d int) {
+	for h := 1; h < len(slc); h++ {
+		l, val := h, slc[h]
+		for ; l > 0 && string(val[d:]) < string(slc[l-1][d:]); l-- {
+			slc[l] = slc[l-1]
+		}
+		if l != h {
+			slc[l] = val
+		}
+	}
+}
+
+// pivotM returns median of n ∈ {3,9} equidistant samples of bytes at depth d,
+// median of three medians for n=9. Assumes len(slc) ≥ 2n.
+func pivotM[S ~[]T, T strLike](slc S, d int, n uint) int {
+	first, step, _ := minMaxSample(uint(len(slc)), n)
+	var md [3]int
+	for i := range md {
+		a := byteAt(slc[first], d)
+		b := byteAt(slc[first+step], d)
+		c := byteAt(slc[first+2*step], d)
+		if md[i] = sixb.Median3(a, b, c); n == 3 {
+			return md[0]
+		}
+		first += 3 * step
+	}
+	return sixb.Median3(md[0], md[1], md[2])
+}
+
+// partM partitions slc on bytes at depth d into < pv, = pv and > pv ranges,
+// returns boundaries of the = range
+func partM[S ~[]T, T strLike](slc S, d, pv int) (l, h int) {
+	h = len(slc)
+	for i := 0; i < h; {
+		c := byteAt(slc[i], d)
+		if c < pv {
+			slc[l], slc[i] = slc[i], slc[l]
+			l++
+			i++
+		} else if c > pv {
+			h--
+			slc[h], slc[i] = slc[i], slc[h]
+		} else {
+			i++
+		}
+	}
+	return
+}
+
+// prefixM returns length of the common prefix of slc members, assumes they share
+// first d bytes
+func prefixM[S ~[]T, T strLike](slc S, d int) int {
+	f, n := slc[0], len(slc[0])
+	for _, s := range slc[1:] {
+		if n = min(n, len(s)); string(s[d:n]) == string(f[d:n]) {
+			continue
+		}
+		for i := d; i < n; i++ {
+			if s[i] != f[i] {
+				n = i
+				break
+			}
+		}
+	}
+	return n
+}
+
+// splitM partitions ar at depth d and returns the <, = and > ranges with their
+// depths, longest range last. The = range is empty if its members end at depth d.
+// Common prefix of ar is skipped in one pass when all members have the same byte.
+func splitM[S ~[]T, T strLike](ar S, d int, n uint) (rs [3]S, ds [3]int) {
+	pv := pivotM(ar, d, n)
+	l, h := partM(ar, d, pv)
+	if l == 0 && h == len(ar) && pv != 0 { // skip common prefix
+		d = prefixM(ar, d+1)
+		pv = pivotM(ar, d, n)
+		l, h = partM(ar, d, pv)
+	}
+
+	rs = [3]S{ar[:l:l], ar[l:h:h], ar[h:]}
+	ds = [3]int{d, d + 1, d}
+	if pv == 0 {
+		rs[1] = nil // all equal
+	}
+	if len(rs[1]) > len(rs[2]) {
+		rs[1], rs[2], ds[1], ds[2] = rs[2], rs[1], ds[2], ds[1]
+	}
+	if len(rs[0]) > len(rs[2]) {
+		rs[0], rs[2], ds[0], ds[2] = rs[2], rs[0], ds[2], ds[0]
+	}
+	return
+}
+
+// short range sort function, assumes lm.ins < len(ar) <= lm.rec, recursive
+func shortM[S ~[]T, T strLike](ar S, d int, lm limits) {
+start:
+	rs, ds := splitM(ar, d, 3)
+
+	for i := range 2 { // shorter ranges are at most half long
+		if len(rs[i]) > lm.ins {
+			shortM(rs[i], ds[i], lm)
+		} else {
+			insertionM(rs[i], ds[i])
+		}
+	}
+
+	if ar, d = rs[2], ds[2]; len(ar) > lm.ins {
+		goto start
+	}
+	insertionM(ar, d)
+}
+
+// new-goroutine sort function
+func gLongM[S ~[]T, T strLike](ar S, d int, lm limits, sv *syncVar) {
+	longM(ar, d, lm, sv)
+
+	if subGor(sv) == 0 { // decrease goroutine counters
+		sv.done <- 0 // we are the last, all done
+	}
+}
+
+// long range sort function, assumes len(ar) > lm.rec, recursive
+func longM[S ~[]T, T strLike](ar S, d int, lm limits, sv *syncVar) {
+start:
+	if stopped(sv) {
+		return
+	}
+	rs, ds := splitM(ar, d, 9)
+
+	for i := range 2 { // shorter ranges are at most half long
+		if len(rs[i]) <= lm.rec {
+			if len(rs[i]) > lm.ins {
+				shortM(rs[i], ds[i], lm)
+			} else {
+				insertionM(rs[i], ds[i])
+			}
+		} else if !claim(sv) { // goroutine quota is full
+			longM(rs[i], ds[i], lm, sv)
+		} else {
+			spawn4(sv.wp, gLongM, rs[i], ds[i], lm, sv)
+		}
+	}
+
+	if ar, d = rs[2], ds[2]; len(ar) > lm.rec {
+		goto start
+	}
+	if len(ar) > lm.ins {
+		shortM(ar, d, lm)
+	} else {
+		insertionM(ar, d)
+	}
+}
+
+// sortM concurrently sorts ar in ascending lexicographic order with multikey
+// quicksort, sorting different ranges in up to *mg goroutines.
+func sortM[S ~[]T, T strLike](ar S, lm limits, mg *uint64, stop *uint32, wp *Pool) {
+
+	if len(ar) <= lm.rec || *mg <= 1 || globalFull() {
+
+		if len(ar) > lm.rec { // single-goroutine sorting
+			longM(ar, 0, lm, serialVar(mg, stop))
+		} else if len(ar) > lm.ins {
+			shortM(ar, 0, lm)
+		} else {
+			insertionM(ar, 0)
+		}
+		return
+	}
+
+	// create channel only when concurrent sorting
+	sv := newSyncVar(mg, stop, wp)
+	longM(ar, 0, lm, sv)
+
+	if atomic.AddUint64(&sv.nGor, ^uint64(0)) != 0 { // decrease goroutine counter
+		<-sv.done // we are not the last, wait
+	}
+	release(sv)
+}
+
+// prefixKey returns first 8 bytes of s as a big-endian integer, zero padded
+func prefixKey[T strLike](s T) (k uint64) {
+	for i := range min(len(s), 8) {
+		k |= uint64(s[i]) << (56 - 8*i)
+	}
+	return
+}
+
+// sortPK concurrently sorts ar in ascending lexicographic order, primarily on cached
+// prefix keys of its members. Ranges with equal keys are then sorted on full members.
+func sortPK[S ~[]T, T strLike](ar S, lm, fc limits, mg *uint64, stop *uint32, wp *Pool) {
+	ks := make([]uint64, len(ar))
+	for i, s := range ar {
+		ks[i] = prefixKey(s)
+	}
+	sortP(ks, ar, lm, mg, stop, wp)
+
+	cv := serialVar(mg, stop)
+	for l := 0; l < len(ks) && !stopped(cv); {
+		h := l + 1
+		for h < len(ks) && ks[h] == ks[l] {
+			h++
+		}
+		if h-l > 1 { // prefix tie
+			sortM(ar[l:h], fc, mg, stop, wp)
+		}
+		l = h
+	}
+}
@@ -8,6 +8,7 @@ package sorty
 
 import (
 	"cmp"
+	"slices"
 
 	"github.com/jfcg/sixb/v2"
 )
@@ -23,6 +24,17 @@ func isSortedO[S ~[]T, T cmp.Ordered](slc S) int {
 	return 0
 }
 
+// isSortedDescO returns 0 if slc is sorted in descending order, otherwise
+// it returns i > 0 with slc[i] > slc[i-1] or either is a NaN, inlined
+func isSortedDescO[S ~[]T, T cmp.Ordered](slc S) int {
+	for i := len(slc) - 1; i > 0; i-- {
+		if !(slc[i] <= slc[i-1]) {
+			return i
+		}
+	}
+	return 0
+}
+
 // insertion sort, inlined
 func insertionO[S ~[]T, T cmp.Ordered](slc S) {
 
@@ -49,6 +61,66 @@ func insertionO[S ~[]T, T cmp.Ordered](slc S) {
 	}
 }
 
+// presortedO returns true if slc is sorted, after reversing it if it is sorted in
+// descending order. It returns at the first out-of-order pair from the end, so it is
+// cheap for unsorted input, inlined
+func presortedO[S ~[]T, T cmp.Ordered](slc S) bool {
+	if isSortedO(slc) == 0 {
+		return true
+	}
+	if isSortedDescO(slc) != 0 {
+		return false
+	}
+	slices.Reverse(slc)
+	return true
+}
+
+// partial insertion sort, gives up when members move by a total distance more than
+// maxShift. Returns true if slc is sorted.
+func partInsertionO[S ~[]T, T cmp.Ordered](slc S) bool {
+	shift := 0
+	for h := 1; h < len(slc); h++ {
+		val := slc[h]
+		if !(val < slc[h-1]) {
+			continue
+		}
+		l := h
+		for ; l > 0 && val < slc[l-1]; l-- {
+			slc[l] = slc[l-1]
+		}
+		slc[l] = val
+		if shift += h - l; shift > maxShift {
+			return false
+		}
+	}
+	return true
+}
+
+// siftO moves slc[i] down the max-heap slc
+func siftO[S ~[]T, T cmp.Ordered](slc S, i int) {
+	for c := 2*i + 1; c < len(slc); c = 2*i + 1 {
+		if c+1 < len(slc) && slc[c] < slc[c+1] {
+			c++
+		}
+		if !(slc[i] < slc[c]) {
+			return
+		}
+		slc[i], slc[c] = slc[c], slc[i]
+		i = c
+	}
+}
+
+// heapsort, used when partitioning gets too deep
+func heapO[S ~[]T, T cmp.Ordered](slc S) {
+	for i := len(slc)>>1 - 1; i >= 0; i-- {
+		siftO(slc, i)
+	}
+	for h := len(slc) - 1; h > 0; h-- {
+		slc[0], slc[h] = slc[h], slc[0]
+		siftO(slc[:h], 0)
+	}
+}
+
 // pivotO selects n equidistant samples from slc that minimizes max distance
 // to non-selected members, then sorts the samples and returns the middle two.
 // Assumes nsConc ≥ n ≥ 5, len(slc) ≥ 2n.
@@ -75,20 +147,20 @@ func pivotO[S ~[]T, T cmp.Ordered](slc S, n uint) (T, T) {
 	return sample[n-1], sample[n]
 }
 
-// partition slc, returns k with slc[:k] ≤ pivot ≤ slc[k:]
+// partition slc, returns k with slc[:k] ≤ pivot ≤ slc[k:] & whether it swapped
 // swap: slc[h] < pv ≤ slc[l]
 // swap: slc[h] ≤ pv < slc[l]
 // next: slc[l] ≤ pv ≤ slc[h]
 //
 //go:nosplit
-func partOneO[S ~[]T, T cmp.Ordered](slc S, pv T) int {
-	l, h := 0, len(slc)-1
+func partOneO[S ~[]T, T cmp.Ordered](slc S, pv T) (int, bool) {
+	l, h, sw := 0, len(slc)-1, false
 	goto start
 second:
 	for {
 		h--
 		if h <= l {
-			return l
+			return l, sw
 		}
 		if slc[h] <= pv {
 			break
@@ -96,6 +168,7 @@ second:
 	}
 swap:
 	slc[l], slc[h] = slc[h], slc[l]
+	sw = true
 next:
 	l++
 	h--
@@ -116,34 +189,54 @@ start:
 		}
 		l++
 		if h <= l {
-			return l + 1
+			return l + 1, sw
 		}
 	}
 last:
 	if l == h && slc[h] < pv { // classify mid element
 		l++
 	}
-	return l
+	return l, sw
+}
+
+// three-way partition slc, returns l, h with slc[:l] < pivot = slc[l:h] < slc[h:]
+func partThreeO[S ~[]T, T cmp.Ordered](slc S, pv T) (l, h int) {
+	h = len(slc)
+	for i := 0; i < h; {
+		if v := slc[i]; v < pv {
+			slc[l], slc[i] = v, slc[l]
+			l++
+			i++
+		} else if pv < v {
+			h--
+			slc[i], slc[h] = slc[h], v
+		} else {
+			i++
+		}
+	}
+	return
 }
 
 // swaps elements to get slc[:l] ≤ pivot ≤ slc[h:]
-// Gap (l,h) expands until one of the intervals is fully consumed.
+// Gap (l,h) expands until one of the intervals is fully consumed. Also returns
+// whether it swapped
 // swap: slc[h] < pv ≤ slc[l]
 // swap: slc[h] ≤ pv < slc[l]
 // next: slc[l] ≤ pv ≤ slc[h]
 //
 //go:nosplit
-func partTwoO[S ~[]T, T cmp.Ordered](slc S, l, h int, pv T) int {
+func partTwoO[S ~[]T, T cmp.Ordered](slc S, l, h int, pv T) (int, bool) {
 	l--
+	sw := false
 	if h <= l {
-		return -1 // will not run
+		return -1, sw // will not run
 	}
 	goto start
 second:
 	for {
 		h++
 		if h >= len(slc) {
-			return l
+			return l, sw
 		}
 		if slc[h] <= pv {
 			break
@@ -151,15 +244,16 @@ second:
 	}
 swap:
 	slc[l], slc[h] = slc[h], slc[l]
+	sw = true
 next:
 	l--
 	h++
 start:
 	if l < 0 {
-		return h
+		return h, sw
 	}
 	if h >= len(slc) {
-		return l
+		return l, sw
 	}
 
 	if pv <= slc[h] { // avoid unnecessary comparisons
@@ -174,7 +268,7 @@ start:
 		}
 		l--
 		if l < 0 {
-			return h
+			return h, sw
 		}
 	}
 }
@@ -182,22 +276,35 @@ start:
 // new-goroutine partition
 //
 //go:nosplit
-func gPartOneO[S ~[]T, T cmp.Ordered](slc S, pv T, ch chan int) {
-	ch <- partOneO(slc, pv)
+func gPartOneO[S ~[]T, T cmp.Ordered](slc S, pv T, sv *syncVar) {
+	k, sw := partOneO(slc, pv)
+	unreserve(sv)
+	sv.done <- packSw(k, sw)
 }
 
-// partition slc in two goroutines, returns k with slc[:k] ≤ pivot ≤ slc[k:]
+// partition slc in two goroutines if budget allows, returns k with
+// slc[:k] ≤ pivot ≤ slc[k:] & whether it swapped
 //
 //go:nosplit
-func partConO[S ~[]T, T cmp.Ordered](slc S, pv T, ch chan int) int {
+func partConO[S ~[]T, T cmp.Ordered](slc S, pv T, sv *syncVar) (int, bool) {
 	mid := len(slc) >> 1
 	l, h := mid>>1, sixb.Mean(mid, len(slc))
 
-	go gPartOneO(slc[l:h:h], pv, ch) // mid half range
+	gor := reserve(sv, 1) != 0 // budget for a new goroutine?
+	if gor {
+		spawn3(sv.wp, gPartOneO, slc[l:h:h], pv, sv) // mid half range
+	}
 
-	r := partTwoO(slc, l, h, pv) // left/right quarter ranges
+	r, sw := partTwoO(slc, l, h, pv) // left/right quarter ranges
 
-	k := l + <-ch // convert returned index to slc
+	k, sm := 0, false
+	if gor {
+		k, sm = unpackSw(<-sv.done)
+	} else {
+		k, sm = partOneO(slc[l:h:h], pv) // mid half range
+	}
+	k += l // convert returned index to slc
+	sw = sw || sm
 
 	// only one gap is possible
 	if r < mid {
@@ -205,6 +312,7 @@ func partConO[S ~[]T, T cmp.Ordered](slc S, pv T, ch chan int) int {
 			if pv < slc[r] {
 				k--
 				slc[r], slc[k] = slc[k], slc[r]
+				sw = true
 			}
 		}
 	} else {
@@ -212,8 +320,9 @@ func partConO[S ~[]T, T cmp.Ordered](slc S, pv T, ch chan int) int {
 			if slc[r] < pv {
 				slc[r], slc[k] = slc[k], slc[r]
 				k++
+				sw = true
 			}
 		}
 	}
-	return k
+	return k, sw
 }
@@ -0,0 +1,537 @@
+/*	Copyright (c) 2026, Serhat Şevki Dinçer.
+	This Source Code Form is subject to the terms of the Mozilla Public
+	License, v. 2.0. If a copy of the MPL was not distributed with this
+	file, You can obtain one at http://mozilla.org/MPL/2.0/.
+*/
+
+package sorty
+
+import (
+	"cmp"
+	"reflect"
+	"slices"
+	"sync/atomic"
+
+	sb "github.com/jfcg/sixb/v2"
+)
+
+// Pair kernels sort keys kr and apply identical moves to values vr, so
+// len(vr) ≥ len(kr) is assumed everywhere.
+
+// insertion sort on pairs
+func insertionP[K cmp.Ordered, V any](kr []K, vr []V) {
+	for h := 1; h < len(kr); h++ {
+		l, key := h, kr[h]
+		if !(key < kr[l-1]) {
+			continue
+		}
+		val := vr[h]
+		for ; l > 0 && key < kr[l-1]; l-- {
+			kr[l], vr[l] = kr[l-1], vr[l-1]
+		}
+		kr[l], vr[l] = key, val
+	}
+}
+
+// presortedP returns true if kr is sorted, after reversing pairs if kr is sorted in
+// descending order. It returns at the first out-of-order pair from the end.
+func presortedP[K cmp.Ordered, V any](kr []K, vr []V) bool {
+	if isSortedO(kr) == 0 {
+		return true
+	}
+	if isSortedDescO(kr) != 0 {
+		return false
+	}
+	slices.Reverse(kr)
+	slices.Reverse(vr[:len(kr)])
+	return true
+}
+
+// partial insertion sort on pairs, gives up when members move by a total distance
+// more than maxShift. Returns true if kr is sorted.
+func partInsertionP[K cmp.Ordered, V any](kr []K, vr []V) bool {
+	shift := 0
+	for h := 1; h < len(kr); h++ {
+		key := kr[h]
+		if !(key < kr[h-1]) {
+			continue
+		}
+		l, val := h, vr[h]
+		for ; l > 0 && key < kr[l-1]; l-- {
+			kr[l], vr[l] = kr[l-1], vr[l-1]
+		}
+		kr[l], vr[l] = key, val
+		if shift += h - l; shift > maxShift {
+			return false
+		}
+	}
+	return true
+}
+
+// siftP moves kr[i] down the max-heap kr
+func siftP[K cmp.Ordered, V any](kr []K, vr []V, i int) {
+	for c := 2*i + 1; c < len(kr); c = 2*i + 1 {
+		if c+1 < len(kr) && kr[c] < kr[c+1] {
+			c++
+		}
+		if !(kr[i] < kr[c]) {
+			return
+		}
+		kr[i], kr[c] = kr[c], kr[i]
+		vr[i], vr[c] = vr[c], vr[i]
+		i = c
+	}
+}
+
+// heapsort on pairs, used when partitioning gets too deep
+func heapP[K cmp.Ordered, V any](kr []K, vr []V) {
+	for i := len(kr)>>1 - 1; i >= 0; i-- {
+		siftP(kr, vr, i)
+	}
+	for h := len(kr) - 1; h > 0; h-- {
+		kr[0], kr[h] = kr[h], kr[0]
+		vr[0], vr[h] = vr[h], vr[0]
+		siftP(kr[:h], vr, 0)
+	}
+}
+
+// partition pairs, returns k with kr[:k] ≤ pivot ≤ kr[k:] & whether it swapped
+// swap: kr[h] < pv ≤ kr[l]
+// swap: kr[h] ≤ pv < kr[l]
+// next: kr[l] ≤ pv ≤ kr[h]
+func partOneP[K cmp.Ordered, V any](kr []K, vr []V, pv K) (int, bool) {
+	l, h, sw := 0, len(kr)-1, false
+	goto start
+second:
+	for {
+		h--
+		if h <= l {
+			return l, sw
+		}
+		if kr[h] <= pv {
+			break
+		}
+	}
+swap:
+	kr[l], kr[h] = kr[h], kr[l]
+	vr[l], vr[h] = vr[h], vr[l]
+	sw = true
+next:
+	l++
+	h--
+start:
+	if h <= l {
+		goto last
+	}
+
+	if pv <= kr[h] { // avoid unnecessary comparisons
+		if pv < kr[l] { // extend ranges in balance
+			goto second
+		}
+		goto next
+	}
+	for {
+		if pv <= kr[l] {
+			goto swap
+		}
+		l++
+		if h <= l {
+			return l + 1, sw
+		}
+	}
+last:
+	if l == h && kr[h] < pv { // classify mid element
+		l++
+	}
+	return l, sw
+}
+
+// swaps pairs to get kr[:l] ≤ pivot ≤ kr[h:]
+// Gap (l,h) expands until one of the intervals is fully consumed. Also returns
+// whether it swapped
+// swap: kr[h] < pv ≤ kr[l]
+// swap: kr[h] ≤ pv < kr[l]
+// next: kr[l] ≤ pv ≤ kr[h]
+func partTwoP[K cmp.Ordered, V any](kr []K, vr []V, l, h int, pv K) (int, bool) {
+	l--
+	sw := false
+	if h <= l {
+		return -1, sw // will not run
+	}
+	goto start
+second:
+	for {
+		h++
+		if h >= len(kr) {
+			return l, sw
+		}
+		if kr[h] <= pv {
+			break
+		}
+	}
+swap:
+	kr[l], kr[h] = kr[h], kr[l]
+	vr[l], vr[h] = vr[h], vr[l]
+	sw = true
+next:
+	l--
+	h++
+start:
+	if l < 0 {
+		return h, sw
+	}
+	if h >= len(kr) {
+		return l, sw
+	}
+
+	if pv <= kr[h] { // avoid unnecessary comparisons
+		if pv < kr[l] { // extend ranges in balance
+			goto second
+		}
+		goto next
+	}
+	for {
+		if pv <= kr[l] {
+			goto swap
+		}
+		l--
+		if l < 0 {
+			return h, sw
+		}
+	}
+}
+
+// new-goroutine partition
+func gPartOneP[K cmp.Ordered, V any](kr []K, vr []V, pv K, sv *syncVar) {
+	k, sw := partOneP(kr, vr, pv)
+	unreserve(sv)
+	sv.done <- packSw(k, sw)
+}
+
+// partition pairs in two goroutines if budget allows, returns k with
+// kr[:k] ≤ pivot ≤ kr[k:] & whether it swapped
+func partConP[K cmp.Ordered, V any](kr []K, vr []V, pv K, sv *syncVar) (int, bool) {
+	mid := len(kr) >> 1
+	l, h := mid>>1, sb.Mean(mid, len(kr))
+
+	gor := reserve(sv, 1) != 0 // budget for a new goroutine?
+	if gor {
+		spawn4(sv.wp, gPartOneP, kr[l:h:h], vr[l:h:h], pv, sv) // mid half range
+	}
+
+	r, sw := partTwoP(kr, vr, l, h, pv) // left/right quarter ranges
+
+	k, sm := 0, false
+	if gor {
+		k, sm = unpackSw(<-sv.done)
+	} else {
+		k, sm = partOneP(kr[l:h:h], vr[l:h:h], pv) // mid half range
+	}
+	k += l // convert returned index to kr
+	sw = sw || sm
+
+	// only one gap is possible
+	if r < mid {
+		for ; 0 <= r; r-- { // gap left in low range?
+			if pv < kr[r] {
+				k--
+				kr[r], kr[k] = kr[k], kr[r]
+				vr[r], vr[k] = vr[k], vr[r]
+				sw = true
+			}
+		}
+	} else {
+		for ; r < len(kr); r++ { // gap left in high range?
+			if kr[r] < pv {
+				kr[r], kr[k] = kr[k], kr[r]
+				vr[r], vr[k] = vr[k], vr[r]
+				k++
+				sw = true
+			}
+		}
+	}
+	return k, sw
+}
+
+// short range sort function, assumes lm.ins < len(kr) <= lm.rec, recursive
+func shortP[K cmp.Ordered, V any](kr []K, vr []V, lm limits) {
+start:
+	if lm.dep--; lm.dep < 0 { // too deep, avoid quadratic time
+		heapP(kr, vr)
+		return
+	}
+	if presortedP(kr, vr) {
+		return
+	}
+	first, step, last := minMaxSample(uint(len(kr)), 3)
+	pv := sb.Median3(kr[first], kr[first+step], kr[last])
+
+	k, _ := partOneP(kr, vr, pv)
+	var kq []K
+	var vq []V
+
+	if k < len(kr)-k {
+		kq, vq = kr[:k:k], vr[:k:k]
+		kr, vr = kr[k:], vr[k:] // kr is the longer range
+	} else {
+		kq, vq = kr[k:], vr[k:]
+		kr, vr = kr[:k:k], vr[:k:k]
+	}
+
+	if len(kq) > lm.ins {
+		shortP(kq, vq, lm) // recurse on the shorter range
+		goto start
+	}
+isort:
+	insertionP(kq, vq) // at least one insertion range
+
+	if len(kr) > lm.ins {
+		goto start
+	}
+	if &kr[0] != &kq[0] {
+		kq, vq = kr, vr
+		goto isort // two insertion ranges
+	}
+}
+
+// new-goroutine sort function
+func gLongP[K cmp.Ordered, V any](kr []K, vr []V, lm limits, sv *syncVar) {
+	longP(kr, vr, lm, sv)
+
+	if subGor(sv) == 0 { // decrease goroutine counters
+		sv.done <- 0 // we are the last, all done
+	}
+}
+
+// long range sort function, assumes len(kr) > lm.rec, recursive
+func longP[K cmp.Ordered, V any](kr []K, vr []V, lm limits, sv *syncVar) {
+start:
+	if stopped(sv) {
+		return
+	}
+	if lm.dep--; lm.dep < 0 { // too deep, avoid quadratic time
+		heapP(kr, vr)
+		return
+	}
+	if presortedP(kr, vr) {
+		return
+	}
+	_, pv := pivotO(kr, nsLong-1) // median-of-n pivot
+	k, sw := partOneP(kr, vr, pv)
+	var kq []K
+	var vq []V
+
+	if k < len(kr)-k {
+		kq, vq = kr[:k:k], vr[:k:k]
+		kr, vr = kr[k:], vr[k:] // kr is the longer range
+	} else {
+		kq, vq = kr[k:], vr[k:]
+		kr, vr = kr[:k:k], vr[:k:k]
+	}
+
+	// balanced partition without swaps, nearly sorted input?
+	if !sw && len(kq) > len(kr)>>3 &&
+		partInsertionP(kq, vq) && partInsertionP(kr, vr) {
+		return
+	}
+
+	// branches below are optimal for fewer total jumps
+	if len(kq) <= lm.rec { // at least one not-long range?
+
+		if len(kq) > lm.ins {
+			shortP(kq, vq, lm)
+		} else {
+			insertionP(kq, vq)
+		}
+
+		if len(kr) > lm.rec { // two not-long ranges?
+			goto start
+		}
+		shortP(kr, vr, lm) // we know len(kr) > lm.ins
+		return
+	}
+
+	// max goroutines? claim a new goroutine otherwise
+	if !claim(sv) {
+		longP(kq, vq, lm, sv) // recurse on the shorter range
+		goto start
+	}
+
+	// new-goroutine sort on the longer range only when
+	// both ranges are big and max goroutines is not exceeded
+	spawn4(sv.wp, gLongP, kr, vr, lm, sv)
+	kr, vr = kq, vq
+	goto start
+}
+
+// sortP concurrently sorts keys kr in ascending order, applying identical moves to
+// values vr. Float keys must not have NaNs.
+func sortP[K cmp.Ordered, V any](kr []K, vr []V, lm limits, mg *uint64, stop *uint32,
+	wp *Pool) {
+	vr = vr[:len(kr)]
+	lm.dep = maxDepth(len(kr))
+
+	if len(kr) < 2*(lm.rec+1) || *mg <= 1 || globalFull() {
+
+		if len(kr) > lm.rec { // single-goroutine sorting
+			longP(kr, vr, lm, serialVar(mg, stop))
+		} else if len(kr) > lm.ins {
+			shortP(kr, vr, lm)
+		} else {
+			insertionP(kr, vr)
+		}
+		return
+	}
+
+	if presortedP(kr, vr) { // nothing to partition
+		return
+	}
+	// create channel only when concurrent partitioning & sorting
+	sv := newSyncVar(mg, stop, wp)
+	for {
+		lm.dep--
+		// concurrent dual partitioning with done
+		_, pv := pivotO(kr, nsConc-1) // median-of-n pivot
+		k, sw := partConP(kr, vr, pv, sv)
+		var kq []K
+		var vq []V
+
+		if k < len(kr)-k {
+			kq, vq = kr[:k:k], vr[:k:k]
+			kr, vr = kr[k:], vr[k:] // kr is the longer range
+		} else {
+			kq, vq = kr[k:], vr[k:]
+			kr, vr = kr[:k:k], vr[:k:k]
+		}
+
+		// balanced partition without swaps, nearly sorted input?
+		if !sw && len(kq) > len(kr)>>3 &&
+			partInsertionP(kq, vq) && partInsertionP(kr, vr) {
+			goto wait // longer range is sorted too
+		}
+
+		// handle shorter range
+		if len(kq) > lm.rec && claim(sv) {
+			spawn4(sv.wp, gLongP, kq, vq, lm, sv)
+
+		} else if len(kq) > lm.rec {
+			longP(kq, vq, lm, sv) // goroutine quota is full
+		} else if len(kq) > lm.ins {
+			shortP(kq, vq, lm)
+		} else {
+			insertionP(kq, vq)
+		}
+
+		// longer range big enough? max goroutines?
+		if len(kr) < 2*(lm.rec+1) || lm.dep <= 0 || gorFull(sv) || stopped(sv) {
+			break
+		}
+		// dual partition longer range
+	}
+
+	longP(kr, vr, lm, sv) // we know len(kr) > lm.rec
+
+wait:
+	if atomic.AddUint64(&sv.nGor, ^uint64(0)) != 0 { // decrease goroutine counter
+		<-sv.done // we are not the last, wait
+	}
+	release(sv)
+}
+
+// nanPartP moves pairs with NaN keys to the start/end according to nan option and
+// returns the range of the rest.
+func nanPartP[K sb.Float, V any](kr []K, vr []V, nan FloatOption) (int, int) {
+	l, h := 0, len(kr)-1
+	if nan == NaNlarge { // move NaNs to the end
+		for l <= h {
+			x := kr[h]
+			if x != x {
+				h--
+				continue
+			}
+			y := kr[l]
+			if y != y {
+				kr[l], kr[h] = x, y
+				vr[l], vr[h] = vr[h], vr[l]
+				h--
+			}
+			l++
+		}
+		return 0, h + 1
+	} else if nan == NaNsmall { // move NaNs to the start
+		for l <= h {
+			y := kr[l]
+			if y != y {
+				l++
+				continue
+			}
+			x := kr[h]
+			if x != x {
+				kr[l], kr[h] = x, y
+				vr[l], vr[h] = vr[h], vr[l]
+				l++
+			}
+			h--
+		}
+		return l, len(kr)
+	}
+	return 0, len(kr)
+}
+
+// sortPairs concurrently sorts keys kr in ascending order with hardware type
+// kernels, applying identical moves to values vr. nan option is taken into account.
+func sortPairs[K cmp.Ordered, V any](kr []K, vr []V, lm, fc limits, mg *uint64, nan FloatOption) {
+	switch kindOf[K]() {
+	case reflect.Int8:
+		sortP(sb.Slice[int8](kr), vr, lm, mg, nil, nil)
+	case reflect.Int16:
+		sortP(sb.Slice[int16](kr), vr, lm, mg, nil, nil)
+	case reflect.Int32:
+		sortP(sb.Slice[int32](kr), vr, lm, mg, nil, nil)
+	case reflect.Int64:
+		sortP(sb.Slice[int64](kr), vr, lm, mg, nil, nil)
+	case reflect.Uint8:
+		sortP(sb.Slice[uint8](kr), vr, lm, mg, nil, nil)
+	case reflect.Uint16:
+		sortP(sb.Slice[uint16](kr), vr, lm, mg, nil, nil)
+	case reflect.Uint32:
+		sortP(sb.Slice[uint32](kr), vr, lm, mg, nil, nil)
+	case reflect.Uint64:
+		sortP(sb.Slice[uint64](kr), vr, lm, mg, nil, nil)
+	case reflect.Float32:
+		ks := sb.Slice[float32](kr)
+		l, h := nanPartP(ks, vr, nan)
+		sortP(ks[l:h], vr[l:h], lm, mg, nil, nil)
+	case reflect.Float64:
+		ks := sb.Slice[float64](kr)
+		l, h := nanPartP(ks, vr, nan)
+		sortP(ks[l:h], vr[l:h], lm, mg, nil, nil)
+	case reflect.String:
+		sortP(sb.Slice[string](kr), vr, fc, mg, nil, nil)
+	}
+}
+
+// SortByKey concurrently sorts s in ascending order of key(s[i]). It calls key()
+// once per member, then sorts extracted keys with type-specific kernels while
+// carrying members along. Float keys are handled according to [NaNoption].
+// Members with equal keys may end up in any order.
+func SortByKey[S ~[]E, E any, K cmp.Ordered](s S, key func(E) K) {
+	keys := make([]K, len(s))
+	for i := range s {
+		keys[i] = key(s[i])
+	}
+	sortPairs(keys, s, limits{MaxLenIns, MaxLenRec, 0},
+		limits{MaxLenInsFC, MaxLenRecFC, 0}, &MaxGor, NaNoption)
+}
+
+// SortPairs concurrently sorts keys in ascending order with type-specific kernels,
+// applying identical swaps to vals. Key types are like [SortOrdered]'s, float keys are
+// handled according to [NaNoption]. Pairs with equal keys may end up in any order.
+// It panics if keys & vals have different lengths.
+func SortPairs[K cmp.Ordered, V any](keys []K, vals []V) {
+	if len(keys) != len(vals) {
+		panic("sorty: SortPairs: different slice lengths")
+	}
+	sortPairs(keys, vals, limits{MaxLenIns, MaxLenRec, 0},
+		limits{MaxLenInsFC, MaxLenRecFC, 0}, &MaxGor, NaNoption)
+}
@@ -0,0 +1,102 @@
+/*	Copyright (c) 2026, Serhat Şevki Dinçer.
+	This Source Code Form is subject to the terms of the Mozilla Public
+	License, v. 2.0. If a copy of the MPL was not distributed with this
+	file, You can obtain one at http://mozilla.org/MPL/2.0/.
+*/
+
+package sorty
+
+import "sync"
+
+// Pool keeps long-lived worker goroutines and synchronization variables that are
+// reused by its sorting calls, instead of creating new goroutines and a channel per
+// concurrent call. This lowers latency of repeated sorts of medium-size slices.
+// When all workers are busy, new goroutines are created as usual. A Pool can be used
+// by multiple goroutines concurrently, also via [Sorter].Pool. Workers run until
+// [Pool.Close], so a Pool must be closed when it is no longer needed, otherwise its
+// idle workers leak.
+type Pool struct {
+	tasks chan func() // idle workers receive tasks, nil stops a worker
+	free  taskList    // reusable tasks
+	vars  sync.Pool   // reusable *syncVar
+	so    Sorter      // parameters of Pool methods
+	nw    int         // number of workers
+}
+
+// NewPool returns a Pool whose methods use up to n goroutines (including caller)
+// per call, like [MaxGor]. It starts n-1 workers. Other parameters are taken from
+// package-level ones. n must be in [1,4096].
+func NewPool(n int) *Pool {
+	if !(4097 > n && n > 0) {
+		panic("sorty: NewPool: invalid number of goroutines")
+	}
+	wp := &Pool{tasks: make(chan func())}
+	wp.vars.New = func() any {
+		return &syncVar{done: make(chan int)}
+	}
+	wp.so = *NewSorter()
+	wp.so.MaxGor = uint64(n)
+	wp.so.Pool = wp
+
+	for wp.nw = n - 1; n > 1; n-- {
+		go wp.work()
+	}
+	return wp
+}
+
+// worker loop
+func (wp *Pool) work() {
+	for f := <-wp.tasks; f != nil; f = <-wp.tasks {
+		f()
+	}
+}
+
+// run gives f to an idle worker, returns false if there is none
+func (wp *Pool) run(f func()) bool {
+	select {
+	case wp.tasks <- f:
+		return true
+	default:
+		return false
+	}
+}
+
+// Go runs fn in an idle worker of wp if any, otherwise in a new goroutine, so wp can
+// be used as an [Executor].
+func (wp *Pool) Go(fn func()) {
+	if !wp.run(fn) {
+		go fn()
+	}
+}
+
+// Close stops wp's workers, waiting for busy ones to finish their tasks. It is
+// required to release them, as workers are not stopped when wp becomes unreachable.
+// Sorting calls overlapping or following Close still work, with new goroutines
+// instead of workers. Close must be called once.
+func (wp *Pool) Close() {
+	for ; wp.nw > 0; wp.nw-- {
+		wp.tasks <- nil
+	}
+}
+
+// SortSlice is like [SortSlice]() with wp's workers.
+func (wp *Pool) SortSlice(ar any) {
+	cf := wp.so.config()
+	if !sortSlice(ar, &cf) {
+		panic("sorty: Pool.SortSlice: invalid input type")
+	}
+}
+
+// SortLen is like [SortLen]() with wp's workers.
+func (wp *Pool) SortLen(ar any) {
+	cf := wp.so.config()
+	if !sortLen(ar, &cf) {
+		panic("sorty: Pool.SortLen: invalid input type")
+	}
+}
+
+// Sort is like [Sort]() with wp's workers.
+func (wp *Pool) Sort(n int, lsw Lesswap) {
+	cf := wp.so.config()
+	sortLesswap(n, lsw, cf.fc, cf.mg, cf.stop, cf.wp)
+}
@@ -0,0 +1,252 @@
+/*	Copyright (c) 2026, Serhat Şevki Dinçer.
+	This Source Code Form is subject to the terms of the Mozilla Public
+	License, v. 2.0. If a copy of the MPL was not distributed with this
+	file, You can obtain one at http://mozilla.org/MPL/2.0/.
+*/
+
+package sorty
+
+import (
+	"unsafe"
+
+	sb "github.com/jfcg/sixb/v2"
+)
+
+const (
+	radixBits = 8              // bits per radix digit
+	radixSize = 1 << radixBits // number of buckets per pass
+	radixPart = 1 << 15        // min part length per goroutine
+)
+
+// radixBuf returns scratch space of length n for radix sort from buf if it is long
+// enough, otherwise allocates it. Returns nil if n is too short for radix sort.
+func radixBuf[T any](n int, buf sb.InSlice) []T {
+	if n < MinLenRadix {
+		return nil
+	}
+	if buf.Len >= uint(n) {
+		return sb.Cast[T](buf)[:n]
+	}
+	return make([]T, n)
+}
+
+// radixParts returns number of parts ar of length n is split into for concurrent
+// radix sorting, at most *mg parts, each at least radixPart long.
+func radixParts(n int, mg *uint64) int {
+	p := n / radixPart
+	if m := *mg; uint64(p) > m {
+		p = int(m)
+	}
+	if p < 1 || globalFull() {
+		p = 1
+	}
+	return p
+}
+
+// partBounds returns boundaries of p equal parts of a collection of length n
+func partBounds(n, p int) []int {
+	b := make([]int, p+1)
+	for i := range b {
+		b[i] = i * n / p
+	}
+	return b
+}
+
+// runParts runs fn(i) for each part i in [0,p), concurrently via sv if p > 1
+func runParts(fn func(int), p int, sv *syncVar, wp *Pool) {
+	if p > 1 {
+		runCon(fn, p, sv, wp)
+	} else {
+		fn(0)
+	}
+}
+
+// radixI sorts ar in ascending order with LSD radix sort, using buf as scratch space.
+// Histogram & scatter phases of each pass are split among up to *mg goroutines.
+// Passes in which all members have the same digit are skipped. Cancellation is
+// checked between passes. Assumes len(buf) ≥ len(ar).
+func radixI[S ~[]T, T sb.Integer](ar, buf S, mg *uint64, stop *uint32,
+	wp *Pool) {
+	var flip uint64 // sign bit for signed types, so digits order like values
+	size := 8 * uint(unsafe.Sizeof(ar[0]))
+	if T(0) > ^T(0) {
+		flip = 1 << (size - 1)
+	}
+
+	p := radixParts(len(ar), mg)
+	b := partBounds(len(ar), p)
+	cnt := make([][radixSize]int, p) // bucket counts/offsets of parts
+
+	// passes are not stopped midway, so ar stays a permutation when cancelled
+	cv := serialVar(mg, stop)
+	sv := syncVar{done: make(chan int), stop: new(uint32)}
+
+	src, dst := ar, buf[:len(ar)]
+	for d := uint(0); d < size && !stopped(cv); d += radixBits {
+		runParts(func(i int) { // histogram of part i
+			c := &cnt[i]
+			*c = [radixSize]int{}
+			for _, v := range src[b[i]:b[i+1]] {
+				c[uint8((uint64(v)^flip)>>d)]++
+			}
+		}, p, &sv, wp)
+
+		// convert counts to offsets, bucket by bucket, part by part
+		sum, skip := 0, false
+		for k := 0; k < radixSize; k++ {
+			s := sum
+			for i := range cnt {
+				c := cnt[i][k]
+				cnt[i][k] = sum
+				sum += c
+			}
+			if sum-s == len(ar) {
+				skip = true // all members have the same digit
+				break
+			}
+		}
+		if skip {
+			continue
+		}
+
+		runParts(func(i int) { // scatter part i
+			c := &cnt[i]
+			for _, v := range src[b[i]:b[i+1]] {
+				k := uint8((uint64(v) ^ flip) >> d)
+				dst[c[k]] = v
+				c[k]++
+			}
+		}, p, &sv, wp)
+		src, dst = dst, src
+	}
+
+	if &src[0] != &ar[0] { // result is in buf?
+		copy(ar, src)
+	}
+}
+
+// radixF sorts ar without NaNs in ascending order with radix sort on
+// order-preserving unsigned keys of its members, using buf as scratch space.
+// Assumes len(buf) ≥ len(ar).
+func radixF[S ~[]T, T sb.Float](ar, buf S, mg *uint64, stop *uint32,
+	wp *Pool) {
+	if unsafe.Sizeof(ar[0]) == 4 {
+		radixK(sb.Slice[uint32](ar), sb.Slice[uint32](buf), mg, stop, wp)
+	} else {
+		radixK(sb.Slice[uint64](ar), sb.Slice[uint64](buf), mg, stop, wp)
+	}
+}
+
+// radixK sorts IEEE-754 bits of floats in ks. They are mapped to keys that order like
+// floats, radix sorted and then mapped back.
+func radixK[U uint32 | uint64](ks, buf []U, mg *uint64, stop *uint32,
+	wp *Pool) {
+	sign := U(1) << (8*unsafe.Sizeof(ks[0]) - 1)
+	for i, u := range ks {
+		if u&sign != 0 {
+			ks[i] = ^u // negative: reverse order
+		} else {
+			ks[i] = u | sign // positive: above negatives
+		}
+	}
+	radixI(ks, buf, mg, stop, wp)
+	for i, u := range ks {
+		if u&sign != 0 {
+			ks[i] = u &^ sign
+		} else {
+			ks[i] = ^u
+		}
+	}
+}
+
+// spanI returns min member of ar and number of values in [min,max] if it is at most
+// len(ar)/MinLenCount, so that counting sort is feasible, otherwise 0. It gives up as
+// soon as the range gets too wide.
+func spanI[S ~[]T, T sb.Integer](ar S) (lo T, span int) {
+	n := uint64(len(ar) / MinLenCount)
+	lo, hi := ar[0], ar[0]
+	for _, v := range ar[1:] {
+		if v < lo {
+			lo = v
+		} else if v > hi {
+			hi = v
+		} else {
+			continue
+		}
+		if uint64(hi)-uint64(lo) >= n {
+			return lo, 0
+		}
+	}
+	return lo, int(uint64(hi)-uint64(lo)) + 1
+}
+
+// countI sorts ar in ascending order with counting sort, assumes members are in
+// [lo, lo+span) and len(ar) < 2^32. Counting & writing phases are split among up to
+// *mg goroutines, with at most one counter per member. Cancellation is checked
+// before writing, so ar is either sorted or unchanged.
+func countI[S ~[]T, T sb.Integer](ar S, lo T, span int, mg *uint64, stop *uint32,
+	wp *Pool) {
+	m := span + 1 // counters per part, c[k+1] for lo+k
+	p := min(radixParts(len(ar), mg), len(ar)/m)
+
+	if p <= 1 {
+		var sc [257]uint32 // small ranges are counted on stack
+		c := sc[:min(m, len(sc))]
+		if m > len(sc) {
+			c = make([]uint32, m)
+		}
+		countPart(ar, lo, c)
+		sumCounts(c)
+		if !stopped(serialVar(mg, stop)) {
+			writePart(ar, lo, c, 0, len(ar))
+		}
+		return
+	}
+	b := partBounds(len(ar), p)
+	cnt := make([]uint32, p*m) // value counts of parts
+	sv := syncVar{done: make(chan int), stop: new(uint32)}
+
+	runParts(func(i int) {
+		countPart(ar[b[i]:b[i+1]], lo, cnt[i*m:(i+1)*m])
+	}, p, &sv, wp)
+
+	// pos[k] is the start of lo+k in sorted ar
+	pos := cnt[:m]
+	for i := m; i < len(cnt); i += m {
+		for k, x := range cnt[i : i+m] {
+			pos[k] += x
+		}
+	}
+	sumCounts(pos)
+	if stopped(serialVar(mg, stop)) {
+		return
+	}
+
+	runParts(func(i int) {
+		writePart(ar, lo, pos, b[i], b[i+1])
+	}, p, &sv, wp)
+}
+
+// countPart counts values of part, c[k+1] for lo+k
+func countPart[S ~[]T, T sb.Integer](part S, lo T, c []uint32) {
+	for _, v := range part {
+		c[uint64(v)-uint64(lo)+1]++
+	}
+}
+
+// sumCounts turns value counts c into start positions
+func sumCounts(c []uint32) {
+	for k := 1; k < len(c); k++ {
+		c[k] += c[k-1]
+	}
+}
+
+// writePart writes ar[j:e] from start positions pos of values lo, lo+1, ..
+func writePart[S ~[]T, T sb.Integer](ar S, lo T, pos []uint32, j, e int) {
+	for k := Search(len(pos)-1, func(k int) bool { return int(pos[k+1]) > j }); j < e; k++ {
+		v, f := lo+T(k), min(int(pos[k+1]), e)
+		for ; j < f; j++ {
+			ar[j] = v
+		}
+	}
+}
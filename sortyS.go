@@ -12,13 +12,20 @@ import (
 	"github.com/jfcg/sixb/v2"
 )
 
-// short range sort function, assumes MaxLenInsFC < len(ar) <= MaxLenRecFC, recursive
-func shortS(ar []string) {
+// short range sort function, assumes lm.ins < len(ar) <= lm.rec, recursive
+func shortS(ar []string, lm limits) {
 start:
+	if lm.dep--; lm.dep < 0 { // too deep, avoid quadratic time
+		heapO(ar)
+		return
+	}
+	if presortedO(ar) {
+		return
+	}
 	first, step, last := minMaxSample(uint(len(ar)), 3)
 	pv := sixb.Median3(ar[first], ar[first+step], ar[last])
 
-	k := partOneO(ar, pv)
+	k, _ := partOneO(ar, pv)
 	var aq []string
 
 	if k < len(ar)-k {
@@ -29,14 +36,14 @@ start:
 		ar = ar[:k:k]
 	}
 
-	if len(aq) > MaxLenInsFC {
-		shortS(aq) // recurse on the shorter range
+	if len(aq) > lm.ins {
+		shortS(aq, lm) // recurse on the shorter range
 		goto start
 	}
 isort:
 	insertionO(aq) // at least one insertion range
 
-	if len(ar) > MaxLenInsFC {
+	if len(ar) > lm.ins {
 		goto start
 	}
 	if &ar[0] != &aq[0] {
@@ -48,21 +55,56 @@ isort:
 // new-goroutine sort function
 //
 //go:nosplit
-func gLongS(ar []string, sv *syncVar) {
-	longS(ar, sv)
+func gLongS(ar []string, lm limits, sv *syncVar) {
+	longS(ar, lm, sv)
 
-	if atomic.AddUint64(&sv.nGor, ^uint64(0)) == 0 { // decrease goroutine counter
+	if subGor(sv) == 0 { // decrease goroutine counters
 		sv.done <- 0 // we are the last, all done
 	}
 }
 
-// long range sort function, assumes len(ar) > MaxLenRecFC, recursive
-func longS(ar []string, sv *syncVar) {
+// long range sort function, assumes len(ar) > lm.rec, recursive
+func longS(ar []string, lm limits, sv *syncVar) {
 start:
-	_, pv := pivotO(ar, nsLong-1) // median-of-n pivot
-	k := partOneO(ar, pv)
+	if stopped(sv) {
+		return
+	}
+	if lm.dep--; lm.dep < 0 { // too deep, avoid quadratic time
+		heapO(ar)
+		return
+	}
+	if presortedO(ar) {
+		return
+	}
+	a, pv := pivotO(ar, nsLong-1) // median-of-n pivot
 	var aq []string
 
+	if a == pv { // many members equal to pivot? exclude them from further sorting
+		l, h := partThreeO(ar, pv)
+		aq, ar = ar[:l:l], ar[h:]
+		if len(aq) > len(ar) {
+			aq, ar = ar, aq // ar is the longer range
+		}
+		if len(aq) > lm.rec {
+			longS(aq, lm, sv)
+		} else if len(aq) > lm.ins {
+			shortS(aq, lm)
+		} else {
+			insertionO(aq)
+		}
+		if len(ar) > lm.rec {
+			goto start
+		}
+		if len(ar) > lm.ins {
+			shortS(ar, lm)
+		} else {
+			insertionO(ar)
+		}
+		return
+	}
+
+	k, sw := partOneO(ar, pv)
+
 	if k < len(ar)-k {
 		aq = ar[:k:k]
 		ar = ar[k:] // ar is the longer range
@@ -71,58 +113,66 @@ start:
 		ar = ar[:k:k]
 	}
 
+	// balanced partition without swaps, nearly sorted input?
+	if !sw && len(aq) > len(ar)>>3 && partInsertionO(aq) && partInsertionO(ar) {
+		return
+	}
+
 	// branches below are optimal for fewer total jumps
-	if len(aq) <= MaxLenRecFC { // at least one not-long range?
+	if len(aq) <= lm.rec { // at least one not-long range?
 
-		if len(aq) > MaxLenInsFC {
-			shortS(aq)
+		if len(aq) > lm.ins {
+			shortS(aq, lm)
 		} else {
 			insertionO(aq)
 		}
 
-		if len(ar) > MaxLenRecFC { // two not-long ranges?
+		if len(ar) > lm.rec { // two not-long ranges?
 			goto start
 		}
-		shortS(ar) // we know len(ar) > MaxLenInsFC
+		shortS(ar, lm) // we know len(ar) > lm.ins
 		return
 	}
 
-	// max goroutines? not atomic but good enough
-	if sv == nil || gorFull(sv) {
-		longS(aq, sv) // recurse on the shorter range
+	// max goroutines? claim a new goroutine otherwise
+	if !claim(sv) {
+		longS(aq, lm, sv) // recurse on the shorter range
 		goto start
 	}
 
 	// new-goroutine sort on the longer range only when
 	// both ranges are big and max goroutines is not exceeded
-	atomic.AddUint64(&sv.nGor, 1) // increase goroutine counter
-	go gLongS(ar, sv)
+	spawn3(sv.wp, gLongS, ar, lm, sv)
 	ar = aq
 	goto start
 }
 
 // sortS concurrently sorts ar in ascending lexicographic order.
-func sortS(ar []string) {
+func sortS(ar []string, lm limits, mg *uint64, stop *uint32, wp *Pool) {
+	lm.dep = maxDepth(len(ar))
 
-	if len(ar) < 2*(MaxLenRecFC+1) || MaxGor <= 1 {
+	if len(ar) < 2*(lm.rec+1) || *mg <= 1 || globalFull() {
 
-		if len(ar) > MaxLenRecFC { // single-goroutine sorting
-			longS(ar, nil)
-		} else if len(ar) > MaxLenInsFC {
-			shortS(ar)
+		if len(ar) > lm.rec { // single-goroutine sorting
+			longS(ar, lm, serialVar(mg, stop))
+		} else if len(ar) > lm.ins {
+			shortS(ar, lm)
 		} else {
 			insertionO(ar)
 		}
 		return
 	}
 
+	if presortedO(ar) { // nothing to partition
+		return
+	}
 	// create channel only when concurrent partitioning & sorting
-	sv := syncVar{1, // number of goroutines including this
-		make(chan int)} // end signal
+	sv := newSyncVar(mg, stop, wp)
 	for {
+		lm.dep--
 		// concurrent dual partitioning with done
 		_, pv := pivotO(ar, nsConc-1) // median-of-n pivot
-		k := partConO(ar, pv, sv.done)
+		k, sw := partConO(ar, pv, sv)
 		var aq []string
 
 		if k < len(ar)-k {
@@ -133,27 +183,35 @@ func sortS(ar []string) {
 			ar = ar[:k:k]
 		}
 
+		// balanced partition without swaps, nearly sorted input?
+		if !sw && len(aq) > len(ar)>>3 && partInsertionO(aq) && partInsertionO(ar) {
+			goto wait // longer range is sorted too
+		}
+
 		// handle shorter range
-		if len(aq) > MaxLenRecFC {
-			atomic.AddUint64(&sv.nGor, 1) // increase goroutine counter
-			go gLongS(aq, &sv)
+		if len(aq) > lm.rec && claim(sv) {
+			spawn3(wp, gLongS, aq, lm, sv)
 
-		} else if len(aq) > MaxLenInsFC {
-			shortS(aq)
+		} else if len(aq) > lm.rec {
+			longS(aq, lm, sv) // goroutine quota is full
+		} else if len(aq) > lm.ins {
+			shortS(aq, lm)
 		} else {
 			insertionO(aq)
 		}
 
 		// longer range big enough? max goroutines?
-		if len(ar) < 2*(MaxLenRecFC+1) || gorFull(&sv) {
+		if len(ar) < 2*(lm.rec+1) || lm.dep <= 0 || gorFull(sv) || stopped(sv) {
 			break
 		}
 		// dual partition longer range
 	}
 
-	longS(ar, &sv) // we know len(ar) > MaxLenRecFC
+	longS(ar, lm, sv) // we know len(ar) > lm.rec
 
+wait:
 	if atomic.AddUint64(&sv.nGor, ^uint64(0)) != 0 { // decrease goroutine counter
 		<-sv.done // we are not the last, wait
 	}
+	release(sv)
 }
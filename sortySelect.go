@@ -0,0 +1,646 @@
+/*	Copyright (c) 2026, Serhat Şevki Dinçer.
+	This Source Code Form is subject to the terms of the Mozilla Public
+	License, v. 2.0. If a copy of the MPL was not distributed with this
+	file, You can obtain one at http://mozilla.org/MPL/2.0/.
+*/
+
+package sorty
+
+import (
+	"cmp"
+	"math"
+	"reflect"
+	"slices"
+	"sync/atomic"
+
+	sb "github.com/jfcg/sixb/v2"
+)
+
+// selectO moves members of ar so that ar[k] is in its sorted position with
+// ar[:k] ≤ ar[k] ≤ ar[k+1:]. Assumes 0 ≤ k < len(ar) and no NaNs in ar.
+func selectO[S ~[]T, T cmp.Ordered](ar S, k int, lm limits) {
+	for len(ar) > lm.ins {
+		_, pv := pivotO(ar, nsLong-1) // median-of-n pivot
+		p, _ := partOneO(ar, pv)
+
+		if k < p { // continue on the range with k
+			ar = ar[:p]
+		} else {
+			ar, k = ar[p:], k-p
+		}
+	}
+	insertionO(ar)
+}
+
+// selectF is like selectO for floats, nan option is taken into account.
+func selectF[S ~[]T, T sb.Float](ar S, k int, lm limits, nan FloatOption) {
+	if l, h := nanPart(ar, nan); l <= k && k < h { // not a NaN position?
+		selectO(ar[l:h], k-l, lm)
+	}
+}
+
+// selectB is like selectO for [][]byte
+func selectB(ar [][]byte, k int, lm limits) {
+	for len(ar) > lm.ins {
+		_, pv := pivotB(ar, nsLong-1) // median-of-n pivot
+		p, _ := partOneB(ar, pv)
+
+		if k < p { // continue on the range with k
+			ar = ar[:p]
+		} else {
+			ar, k = ar[p:], k-p
+		}
+	}
+	insertionB(ar)
+}
+
+// selectLsw moves members of underlying collection so that k-th member is in its
+// sorted position among [lo,hi]. Assumes lo ≤ k ≤ hi.
+func selectLsw(lsw Lesswap, lo, hi, k int, lm limits) {
+	for hi-lo >= lm.ins {
+		pv := pivot(lsw, lo, hi, nsLong-1) // median-of-n pivot
+		l, _ := partOne(lsw, lo+1, pv, hi-1)
+
+		if k < l { // continue on the range with k
+			hi = l - 1
+		} else {
+			lo = l
+		}
+	}
+	insertion(lsw, lo, hi)
+}
+
+// rangeI sorts ar of any length with sortI's kernels
+func rangeI[S ~[]T, T sb.Integer](ar S, lm limits, sv *syncVar) {
+	if len(ar) > lm.rec {
+		longI(ar, lm, sv)
+	} else if len(ar) > lm.ins {
+		shortI(ar, lm)
+	} else {
+		insertionO(ar)
+	}
+}
+
+// rangeF is like rangeI for floats without NaNs
+func rangeF[S ~[]T, T sb.Float](ar S, lm limits, sv *syncVar) {
+	if len(ar) > lm.rec {
+		longF(ar, lm, sv)
+	} else if len(ar) > lm.ins {
+		shortF(ar, lm)
+	} else {
+		insertionO(ar)
+	}
+}
+
+// rangeS is like rangeI for strings
+func rangeS(ar []string, lm limits, sv *syncVar) {
+	if len(ar) > lm.rec {
+		longS(ar, lm, sv)
+	} else if len(ar) > lm.ins {
+		shortS(ar, lm)
+	} else {
+		insertionO(ar)
+	}
+}
+
+// rangeB is like rangeI for [][]byte
+func rangeB(ar [][]byte, lm limits, sv *syncVar) {
+	if len(ar) > lm.rec {
+		longB(ar, lm, sv)
+	} else if len(ar) > lm.ins {
+		shortB(ar, lm)
+	} else {
+		insertionB(ar)
+	}
+}
+
+// new-goroutine range sort function
+func gRange[S any](ar S, lm limits, sv *syncVar, srt func(S, limits, *syncVar)) {
+	srt(ar, lm, sv)
+
+	if subGor(sv) == 0 { // decrease goroutine counters
+		sv.done <- 0 // we are the last, all done
+	}
+}
+
+// topO sorts smallest k members of ar into ar[:k] with range sort function srt(),
+// assumes 0 < k < len(ar). Ranges beyond k are pruned, ranges within k are sorted
+// in new goroutines when possible, recursive
+func topO[S ~[]T, T cmp.Ordered](ar S, k int, lm limits, sv *syncVar,
+	srt func(S, limits, *syncVar)) {
+start:
+	if k >= len(ar) { // all of ar is needed
+		srt(ar, lm, sv)
+		return
+	}
+	if len(ar) <= lm.rec || lm.dep <= 0 {
+		selectO(ar, k-1, lm)
+		srt(ar[:k-1], lm, sv)
+		return
+	}
+	lm.dep--
+	_, pv := pivotO(ar, nsLong-1) // median-of-n pivot
+	p, _ := partOneO(ar, pv)
+
+	if k <= p { // prune ar[p:]
+		ar = ar[:p]
+		goto start
+	}
+	aq := ar[:p:p] // all of aq is needed
+	ar, k = ar[p:], k-p
+
+	// max goroutines? claim a new goroutine otherwise
+	if len(aq) <= lm.rec || !claim(sv) {
+		srt(aq, lm, sv)
+	} else {
+		spawn4(sv.wp, gRange, aq, lm, sv, srt)
+	}
+	goto start
+}
+
+// topB is like topO for [][]byte
+func topB(ar [][]byte, k int, lm limits, sv *syncVar, srt func([][]byte, limits, *syncVar)) {
+start:
+	if k >= len(ar) { // all of ar is needed
+		srt(ar, lm, sv)
+		return
+	}
+	if len(ar) <= lm.rec || lm.dep <= 0 {
+		selectB(ar, k-1, lm)
+		srt(ar[:k-1], lm, sv)
+		return
+	}
+	lm.dep--
+	_, pv := pivotB(ar, nsLong-1) // median-of-n pivot
+	p, _ := partOneB(ar, pv)
+
+	if k <= p { // prune ar[p:]
+		ar = ar[:p]
+		goto start
+	}
+	aq := ar[:p:p] // all of aq is needed
+	ar, k = ar[p:], k-p
+
+	// max goroutines? claim a new goroutine otherwise
+	if len(aq) <= lm.rec || !claim(sv) {
+		srt(aq, lm, sv)
+	} else {
+		spawn4(sv.wp, gRange, aq, lm, sv, srt)
+	}
+	goto start
+}
+
+// runTop runs top-k function fn with range sort function srt on ar, concurrently if
+// possible. Assumes 0 < k < len(ar).
+func runTop[S ~[]T, T any](fn func(S, int, limits, *syncVar, func(S, limits, *syncVar)),
+	srt func(S, limits, *syncVar), ar S, k int, lm limits, mg *uint64) {
+
+	lm.dep = maxDepth(len(ar))
+	if len(ar) < 2*(lm.rec+1) || *mg <= 1 || globalFull() {
+		fn(ar, k, lm, nil, srt) // single-goroutine partial sort
+		return
+	}
+
+	// create channel only when concurrent partial sort
+	sv := newSyncVar(mg, nil, nil)
+	fn(ar, k, lm, sv, srt)
+
+	if atomic.AddUint64(&sv.nGor, ^uint64(0)) != 0 { // decrease goroutine counter
+		<-sv.done // we are not the last, wait
+	}
+	release(sv)
+}
+
+// partialI concurrently sorts smallest k members of ar into ar[:k]
+func partialI[S ~[]T, T sb.Integer](ar S, k int, lm limits, mg *uint64) {
+	if k >= len(ar) {
+		sortI(ar, nil, lm, mg, nil, nil)
+	} else if k > 0 {
+		runTop(topO[S], rangeI[S], ar, k, lm, mg)
+	}
+}
+
+// partialF is like partialI for floats, nan option is taken into account.
+func partialF[S ~[]T, T sb.Float](ar S, k int, lm limits, mg *uint64, nan FloatOption) {
+	l, h := nanPart(ar, nan)
+	ar, k = ar[l:h], k-l // NaNs at the start are already in place
+
+	if k >= len(ar) {
+		sortF(ar, nil, lm, mg, NaNignore, nil, nil) // no NaNs left
+	} else if k > 0 {
+		runTop(topO[S], rangeF[S], ar, k, lm, mg)
+	}
+}
+
+// partialS is like partialI for strings
+func partialS(ar []string, k int, lm limits, mg *uint64) {
+	if k >= len(ar) {
+		sortS(ar, lm, mg, nil, nil)
+	} else if k > 0 {
+		runTop(topO[[]string], rangeS, ar, k, lm, mg)
+	}
+}
+
+// partialB is like partialI for [][]byte
+func partialB(ar [][]byte, k int, lm limits, mg *uint64) {
+	if k >= len(ar) {
+		sortB(ar, lm, mg, nil, nil)
+	} else if k > 0 {
+		runTop(topB, rangeB, ar, k, lm, mg)
+	}
+}
+
+// sortPartial concurrently sorts smallest k members of ar into ar[:k].
+// Returns false for invalid input types.
+func sortPartial(ar any, k int, cf *config) bool {
+	slc, kind := extractSK(ar)
+	switch kind {
+	case reflect.Int8:
+		partialI(sb.Cast[int8](slc), k, cf.lm, cf.mg)
+	case reflect.Int16:
+		partialI(sb.Cast[int16](slc), k, cf.lm, cf.mg)
+	case reflect.Int32:
+		partialI(sb.Cast[int32](slc), k, cf.lm, cf.mg)
+	case reflect.Int64:
+		partialI(sb.Cast[int64](slc), k, cf.lm, cf.mg)
+	case reflect.Uint8:
+		partialI(sb.Cast[uint8](slc), k, cf.lm, cf.mg)
+	case reflect.Uint16:
+		partialI(sb.Cast[uint16](slc), k, cf.lm, cf.mg)
+	case reflect.Uint32:
+		partialI(sb.Cast[uint32](slc), k, cf.lm, cf.mg)
+	case reflect.Uint64:
+		partialI(sb.Cast[uint64](slc), k, cf.lm, cf.mg)
+	case reflect.Float32:
+		partialF(sb.Cast[float32](slc), k, cf.lm, cf.mg, cf.nan)
+	case reflect.Float64:
+		partialF(sb.Cast[float64](slc), k, cf.lm, cf.mg, cf.nan)
+	case sliceBias + reflect.Uint8: // [][]byte
+		partialB(sb.Cast[[]byte](slc), k, cf.fc, cf.mg)
+	case reflect.String:
+		partialS(sb.Cast[string](slc), k, cf.fc, cf.mg)
+	default:
+		return false
+	}
+	return true
+}
+
+// SortPartial concurrently sorts smallest k members of ar in ascending order into
+// ar[:k], rest of ar ends up in arbitrary order. Members beyond k are not sorted, so
+// it is faster than [SortSlice] for small k. k ≥ len(ar) sorts all of ar, k ≤ 0 is
+// a no-op. ar's (underlying) type can be
+//
+//	[]int, []int8, []int16, []int32, []int64, []uint, []uint8, []uint16,
+//	[]uint32, []uint64, []bool, []uintptr, []float32, []float64, []string, [][]byte,
+//	[]unsafe.Pointer, []*T // for any type T
+//
+// otherwise it panics.
+func SortPartial(ar any, k int) {
+	cf := defConfig()
+	if !sortPartial(ar, k, &cf) {
+		panic("sorty: SortPartial: invalid input type")
+	}
+}
+
+// SortPartialOrdered is like [SortPartial] with a type-checked generic input
+// like [SortOrdered].
+func SortPartialOrdered[S ~[]T, T cmp.Ordered](s S, k int) {
+	lm, mg := limits{MaxLenIns, MaxLenRec, 0}, &MaxGor
+	switch kindOf[T]() {
+	case reflect.Int8:
+		partialI(sb.Slice[int8](s), k, lm, mg)
+	case reflect.Int16:
+		partialI(sb.Slice[int16](s), k, lm, mg)
+	case reflect.Int32:
+		partialI(sb.Slice[int32](s), k, lm, mg)
+	case reflect.Int64:
+		partialI(sb.Slice[int64](s), k, lm, mg)
+	case reflect.Uint8:
+		partialI(sb.Slice[uint8](s), k, lm, mg)
+	case reflect.Uint16:
+		partialI(sb.Slice[uint16](s), k, lm, mg)
+	case reflect.Uint32:
+		partialI(sb.Slice[uint32](s), k, lm, mg)
+	case reflect.Uint64:
+		partialI(sb.Slice[uint64](s), k, lm, mg)
+	case reflect.Float32:
+		partialF(sb.Slice[float32](s), k, lm, mg, NaNoption)
+	case reflect.Float64:
+		partialF(sb.Slice[float64](s), k, lm, mg, NaNoption)
+	case reflect.String:
+		partialS(sb.Slice[string](s), k, limits{MaxLenInsFC, MaxLenRecFC, 0}, mg)
+	}
+}
+
+// SortPartialLsw is like [SortPartial] for underlying collection of length n via
+// lsw(), see [Sort].
+func SortPartialLsw(n, k int, lsw Lesswap) {
+	lm := limits{MaxLenInsFC, MaxLenRecFC, 0}
+	if k < n {
+		if k <= 0 {
+			return
+		}
+		selectLsw(lsw, 0, n-1, k-1, lm)
+		n = k - 1
+	}
+	sortLesswap(n, lsw, lm, &MaxGor, nil, nil)
+}
+
+// selectSlice moves k-th smallest member of ar into ar[k] with smaller ones before and
+// larger ones after. Returns false for invalid input types.
+func selectSlice(ar any, k int, cf *config) bool {
+	slc, kind := extractSK(ar)
+	if uint(k) >= slc.Len && kind != reflect.Invalid {
+		panic("sorty: Select: k out of range")
+	}
+	switch kind {
+	case reflect.Int8:
+		selectO(sb.Cast[int8](slc), k, cf.lm)
+	case reflect.Int16:
+		selectO(sb.Cast[int16](slc), k, cf.lm)
+	case reflect.Int32:
+		selectO(sb.Cast[int32](slc), k, cf.lm)
+	case reflect.Int64:
+		selectO(sb.Cast[int64](slc), k, cf.lm)
+	case reflect.Uint8:
+		selectO(sb.Cast[uint8](slc), k, cf.lm)
+	case reflect.Uint16:
+		selectO(sb.Cast[uint16](slc), k, cf.lm)
+	case reflect.Uint32:
+		selectO(sb.Cast[uint32](slc), k, cf.lm)
+	case reflect.Uint64:
+		selectO(sb.Cast[uint64](slc), k, cf.lm)
+	case reflect.Float32:
+		selectF(sb.Cast[float32](slc), k, cf.lm, cf.nan)
+	case reflect.Float64:
+		selectF(sb.Cast[float64](slc), k, cf.lm, cf.nan)
+	case sliceBias + reflect.Uint8: // [][]byte
+		selectB(sb.Cast[[]byte](slc), k, cf.fc)
+	case reflect.String:
+		selectO(sb.Cast[string](slc), k, cf.fc)
+	default:
+		return false
+	}
+	return true
+}
+
+// Select moves k-th smallest member of ar (0-based) into ar[k], with smaller or equal
+// members before and greater or equal members after it, in expected linear time.
+// For example Select(ar, len(ar)/2) computes a median without sorting ar.
+// ar's (underlying) type can be
+//
+//	[]int, []int8, []int16, []int32, []int64, []uint, []uint8, []uint16,
+//	[]uint32, []uint64, []bool, []uintptr, []float32, []float64, []string, [][]byte,
+//	[]unsafe.Pointer, []*T // for any type T
+//
+// otherwise it panics. It also panics if k is not in [0,len(ar)).
+func Select(ar any, k int) {
+	cf := defConfig()
+	if !selectSlice(ar, k, &cf) {
+		panic("sorty: Select: invalid input type")
+	}
+}
+
+// SelectOrdered is like [Select] with a type-checked generic input like [SortOrdered].
+func SelectOrdered[S ~[]T, T cmp.Ordered](s S, k int) {
+	if uint(k) >= uint(len(s)) {
+		panic("sorty: SelectOrdered: k out of range")
+	}
+	lm := limits{MaxLenIns, MaxLenRec, 0}
+	switch kindOf[T]() {
+	case reflect.Float32:
+		selectF(sb.Slice[float32](s), k, lm, NaNoption)
+	case reflect.Float64:
+		selectF(sb.Slice[float64](s), k, lm, NaNoption)
+	case reflect.String:
+		selectO(s, k, limits{MaxLenInsFC, MaxLenRecFC, 0})
+	default:
+		selectO(s, k, lm)
+	}
+}
+
+// SelectLsw is like [Select] for underlying collection of length n via lsw(),
+// see [Sort].
+func SelectLsw(n, k int, lsw Lesswap) {
+	if uint(k) >= uint(n) {
+		panic("sorty: SelectLsw: k out of range")
+	}
+	selectLsw(lsw, 0, n-1, k, limits{MaxLenInsFC, MaxLenRecFC, 0})
+}
+
+// splitRanks splits ascending ranks ks at partition index p, returns ks[:i] < p and
+// ks[i:] ≥ p, latter converted to ranks in ar[p:].
+func splitRanks(ks []int, p int) (lo, hi []int) {
+	i, _ := slices.BinarySearch(ks, p)
+	lo, hi = ks[:i:i], ks[i:]
+	for j := range hi {
+		hi[j] -= p
+	}
+	return
+}
+
+// new-goroutine multi-select function
+func gMultiO[S ~[]T, T cmp.Ordered](ar S, ks []int, lm limits, sv *syncVar) {
+	multiO(ar, ks, lm, sv)
+
+	if subGor(sv) == 0 { // decrease goroutine counters
+		sv.done <- 0 // we are the last, all done
+	}
+}
+
+// multiO moves members of ar so that ar[k] is in its sorted position for each k in
+// ascending ranks ks ⊂ [0,len(ar)). Only recurses into ranges with ranks, recursive
+func multiO[S ~[]T, T cmp.Ordered](ar S, ks []int, lm limits, sv *syncVar) {
+start:
+	if len(ks) == 1 || len(ar) <= lm.ins {
+		selectO(ar, ks[0], lm) // just insertion sort for short ar
+		return
+	}
+	_, pv := pivotO(ar, nsLong-1) // median-of-n pivot
+	p, _ := partOneO(ar, pv)
+	kq, ks := splitRanks(ks, p)
+	aq := ar[:p:p]
+	ar = ar[p:]
+
+	if len(kq) == 0 { // continue on the range(s) with ranks
+		goto start
+	}
+	if len(ks) == 0 {
+		ar, ks = aq, kq
+		goto start
+	}
+
+	// ranges big enough? max goroutines? claim a new goroutine otherwise
+	if len(aq) <= lm.rec || len(ar) <= lm.rec || !claim(sv) {
+		multiO(aq, kq, lm, sv)
+		goto start
+	}
+	spawn4(sv.wp, gMultiO, aq, kq, lm, sv)
+	goto start
+}
+
+// new-goroutine multi-select function
+func gMultiB(ar [][]byte, ks []int, lm limits, sv *syncVar) {
+	multiB(ar, ks, lm, sv)
+
+	if subGor(sv) == 0 { // decrease goroutine counters
+		sv.done <- 0 // we are the last, all done
+	}
+}
+
+// multiB is like multiO for [][]byte
+func multiB(ar [][]byte, ks []int, lm limits, sv *syncVar) {
+start:
+	if len(ks) == 1 || len(ar) <= lm.ins {
+		selectB(ar, ks[0], lm) // just insertion sort for short ar
+		return
+	}
+	_, pv := pivotB(ar, nsLong-1) // median-of-n pivot
+	p, _ := partOneB(ar, pv)
+	kq, ks := splitRanks(ks, p)
+	aq := ar[:p:p]
+	ar = ar[p:]
+
+	if len(kq) == 0 { // continue on the range(s) with ranks
+		goto start
+	}
+	if len(ks) == 0 {
+		ar, ks = aq, kq
+		goto start
+	}
+
+	// ranges big enough? max goroutines? claim a new goroutine otherwise
+	if len(aq) <= lm.rec || len(ar) <= lm.rec || !claim(sv) {
+		multiB(aq, kq, lm, sv)
+		goto start
+	}
+	spawn4(sv.wp, gMultiB, aq, kq, lm, sv)
+	goto start
+}
+
+// runMulti runs multi-select function fn on ar of length n with ascending ranks ks,
+// concurrently if possible
+func runMulti[S any](fn func(S, []int, limits, *syncVar), ar S, n int, ks []int,
+	lm limits, mg *uint64) {
+
+	if len(ks) == 0 {
+		return
+	}
+	if len(ks) == 1 || n < 2*(lm.rec+1) || *mg <= 1 || globalFull() {
+		fn(ar, ks, lm, nil) // single-goroutine multi-select
+		return
+	}
+
+	// create channel only when concurrent multi-select
+	sv := newSyncVar(mg, nil, nil)
+	fn(ar, ks, lm, sv)
+
+	if atomic.AddUint64(&sv.nGor, ^uint64(0)) != 0 { // decrease goroutine counter
+		<-sv.done // we are not the last, wait
+	}
+	release(sv)
+}
+
+// multiF is like multiO for floats, nan option is taken into account.
+func multiF[S ~[]T, T sb.Float](ar S, ks []int, lm limits, mg *uint64, nan FloatOption) {
+	l, h := nanPart(ar, nan)
+	i, _ := slices.BinarySearch(ks, l)
+	k, _ := slices.BinarySearch(ks, h)
+	ks = ks[i:k] // ignore NaN positions
+	for j := range ks {
+		ks[j] -= l
+	}
+	runMulti(multiO[S], ar[l:h], h-l, ks, lm, mg)
+}
+
+// selectMany moves members of ar so that ar[k] is in its sorted position for each k in
+// ks. Returns false for invalid input types.
+func selectMany(ar any, ks []int, cf *config) bool {
+	slc, kind := extractSK(ar)
+	if kind == reflect.Invalid {
+		return false
+	}
+	ks = slices.Clone(ks)
+	slices.Sort(ks)
+	ks = slices.Compact(ks)
+	if len(ks) > 0 && (ks[0] < 0 || uint(ks[len(ks)-1]) >= slc.Len) {
+		panic("sorty: SelectMany: rank out of range")
+	}
+
+	n := int(slc.Len)
+	switch kind {
+	case reflect.Int8:
+		runMulti(multiO[[]int8], sb.Cast[int8](slc), n, ks, cf.lm, cf.mg)
+	case reflect.Int16:
+		runMulti(multiO[[]int16], sb.Cast[int16](slc), n, ks, cf.lm, cf.mg)
+	case reflect.Int32:
+		runMulti(multiO[[]int32], sb.Cast[int32](slc), n, ks, cf.lm, cf.mg)
+	case reflect.Int64:
+		runMulti(multiO[[]int64], sb.Cast[int64](slc), n, ks, cf.lm, cf.mg)
+	case reflect.Uint8:
+		runMulti(multiO[[]uint8], sb.Cast[uint8](slc), n, ks, cf.lm, cf.mg)
+	case reflect.Uint16:
+		runMulti(multiO[[]uint16], sb.Cast[uint16](slc), n, ks, cf.lm, cf.mg)
+	case reflect.Uint32:
+		runMulti(multiO[[]uint32], sb.Cast[uint32](slc), n, ks, cf.lm, cf.mg)
+	case reflect.Uint64:
+		runMulti(multiO[[]uint64], sb.Cast[uint64](slc), n, ks, cf.lm, cf.mg)
+	case reflect.Float32:
+		multiF(sb.Cast[float32](slc), ks, cf.lm, cf.mg, cf.nan)
+	case reflect.Float64:
+		multiF(sb.Cast[float64](slc), ks, cf.lm, cf.mg, cf.nan)
+	case sliceBias + reflect.Uint8: // [][]byte
+		runMulti(multiB, sb.Cast[[]byte](slc), n, ks, cf.fc, cf.mg)
+	case reflect.String:
+		runMulti(multiO[[]string], sb.Cast[string](slc), n, ks, cf.fc, cf.mg)
+	default:
+		return false
+	}
+	return true
+}
+
+// SelectMany concurrently moves members of ar so that ar[k] is the k-th smallest
+// member (0-based) for each k in ks, in one pass like [Select] for each k. Members
+// between consecutive ranks stay between them, in arbitrary order. ks is not modified.
+// ar's (underlying) type can be
+//
+//	[]int, []int8, []int16, []int32, []int64, []uint, []uint8, []uint16,
+//	[]uint32, []uint64, []bool, []uintptr, []float32, []float64, []string, [][]byte,
+//	[]unsafe.Pointer, []*T // for any type T
+//
+// otherwise it panics. It also panics if a rank is not in [0,len(ar)).
+func SelectMany(ar any, ks []int) {
+	cf := defConfig()
+	if !selectMany(ar, ks, &cf) {
+		panic("sorty: SelectMany: invalid input type")
+	}
+}
+
+// Quantiles concurrently computes ranks k = round(q*(len(ar)-1)) for each q in qs,
+// places them via [SelectMany] and returns them, so ar[ks[i]] is the qs[i]-quantile
+// of ar. For example ks := Quantiles(ar, []float64{.5, .9, .99}) gives p50, p90 & p99.
+// NaNs are placed according to [NaNoption]. ar's (underlying) type can be like
+// [SelectMany]'s, otherwise it panics. It also panics if a q is not in [0,1], or if
+// ar is empty and qs is not.
+func Quantiles(ar any, qs []float64) []int {
+	slc, kind := extractSK(ar)
+	if kind == reflect.Invalid {
+		panic("sorty: Quantiles: invalid input type")
+	}
+	ks := make([]int, len(qs))
+	for i, q := range qs {
+		if !(0 <= q && q <= 1) {
+			panic("sorty: Quantiles: q not in [0,1]")
+		}
+		if slc.Len == 0 {
+			panic("sorty: Quantiles: empty input")
+		}
+		ks[i] = int(math.Round(q * float64(slc.Len-1)))
+	}
+
+	cf := defConfig()
+	if !selectMany(ar, ks, &cf) {
+		panic("sorty: Quantiles: invalid input type")
+	}
+	return ks
+}
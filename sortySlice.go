@@ -7,71 +7,398 @@
 package sorty
 
 import (
+	"bytes"
+	"cmp"
+	"context"
 	"reflect"
+	"slices"
 
 	sb "github.com/jfcg/sixb/v2"
 )
 
+// isSortedSlice returns 0 if ar is sorted in ascending/descending order, otherwise
+// it returns i > 0 with ar[i] < ar[i-1] (or > for descending). Returns -1 for
+// invalid input types.
+//
+//go:nosplit
+func isSortedSlice(ar any, cf *config) int {
+	slc, kind := extractSK(ar)
+	if cf.desc {
+		switch kind {
+		case reflect.Int8:
+			return isSortedDescO(sb.Cast[int8](slc))
+		case reflect.Int16:
+			return isSortedDescO(sb.Cast[int16](slc))
+		case reflect.Int32:
+			return isSortedDescO(sb.Cast[int32](slc))
+		case reflect.Int64:
+			return isSortedDescO(sb.Cast[int64](slc))
+		case reflect.Uint8:
+			return isSortedDescO(sb.Cast[uint8](slc))
+		case reflect.Uint16:
+			return isSortedDescO(sb.Cast[uint16](slc))
+		case reflect.Uint32:
+			return isSortedDescO(sb.Cast[uint32](slc))
+		case reflect.Uint64:
+			return isSortedDescO(sb.Cast[uint64](slc))
+		case reflect.Float32:
+			return isSortedDescF(sb.Cast[float32](slc), cf.nan)
+		case reflect.Float64:
+			return isSortedDescF(sb.Cast[float64](slc), cf.nan)
+		case sliceBias + reflect.Uint8: // [][]byte
+			return isSortedDescB(sb.Cast[[]byte](slc))
+		case reflect.String:
+			return isSortedDescO(sb.Cast[string](slc))
+		}
+		return -1
+	}
+	switch kind {
+	case reflect.Int8:
+		return isSortedO(sb.Cast[int8](slc))
+	case reflect.Int16:
+		return isSortedO(sb.Cast[int16](slc))
+	case reflect.Int32:
+		return isSortedO(sb.Cast[int32](slc))
+	case reflect.Int64:
+		return isSortedO(sb.Cast[int64](slc))
+	case reflect.Uint8:
+		return isSortedO(sb.Cast[uint8](slc))
+	case reflect.Uint16:
+		return isSortedO(sb.Cast[uint16](slc))
+	case reflect.Uint32:
+		return isSortedO(sb.Cast[uint32](slc))
+	case reflect.Uint64:
+		return isSortedO(sb.Cast[uint64](slc))
+	case reflect.Float32:
+		return isSortedF(sb.Cast[float32](slc), cf.nan)
+	case reflect.Float64:
+		return isSortedF(sb.Cast[float64](slc), cf.nan)
+	case sliceBias + reflect.Uint8: // [][]byte
+		return isSortedB(sb.Cast[[]byte](slc))
+	case reflect.String:
+		return isSortedO(sb.Cast[string](slc))
+	}
+	return -1
+}
+
 // IsSortedSlice returns 0 if ar is sorted in ascending order, otherwise
 // it returns i > 0 with ar[i] < ar[i-1]. ar's (underlying) type can be
 //
-//	[]int, []int32, []int64, []uint, []uint32, []uint64,
-//	[]uintptr, []float32, []float64, []string, [][]byte,
+//	[]int, []int8, []int16, []int32, []int64, []uint, []uint8, []uint16,
+//	[]uint32, []uint64, []bool, []uintptr, []float32, []float64, []string, [][]byte,
 //	[]unsafe.Pointer, []*T // for any type T
 //
 // otherwise it panics.
 //
 //go:nosplit
 func IsSortedSlice(ar any) int {
+	cf := defConfig()
+	i := isSortedSlice(ar, &cf)
+	if i < 0 {
+		panic("sorty: IsSortedSlice: invalid input type")
+	}
+	return i
+}
+
+// IsSortedSliceDesc returns 0 if ar is sorted in descending order, otherwise
+// it returns i > 0 with ar[i] > ar[i-1]. ar's (underlying) type can be
+//
+//	[]int, []int8, []int16, []int32, []int64, []uint, []uint8, []uint16,
+//	[]uint32, []uint64, []bool, []uintptr, []float32, []float64, []string, [][]byte,
+//	[]unsafe.Pointer, []*T // for any type T
+//
+// otherwise it panics.
+//
+//go:nosplit
+func IsSortedSliceDesc(ar any) int {
+	cf := defConfig()
+	cf.desc = true
+	i := isSortedSlice(ar, &cf)
+	if i < 0 {
+		panic("sorty: IsSortedSliceDesc: invalid input type")
+	}
+	return i
+}
+
+// sortSlice concurrently sorts ar in ascending/descending order.
+// Returns false for invalid input types.
+func sortSlice(ar any, cf *config) bool {
 	slc, kind := extractSK(ar)
 	switch kind {
+	case reflect.Int8:
+		sortI(sb.Cast[int8](slc), nil, cf.lm, cf.mg, cf.stop, cf.wp)
+	case reflect.Int16:
+		sortI(sb.Cast[int16](slc), nil, cf.lm, cf.mg, cf.stop, cf.wp)
 	case reflect.Int32:
-		return isSortedO(sb.Cast[int32](slc))
+		sortI(sb.Cast[int32](slc), nil, cf.lm, cf.mg, cf.stop, cf.wp)
 	case reflect.Int64:
-		return isSortedO(sb.Cast[int64](slc))
+		sortI(sb.Cast[int64](slc), nil, cf.lm, cf.mg, cf.stop, cf.wp)
+	case reflect.Uint8:
+		sortI(sb.Cast[uint8](slc), nil, cf.lm, cf.mg, cf.stop, cf.wp)
+	case reflect.Uint16:
+		sortI(sb.Cast[uint16](slc), nil, cf.lm, cf.mg, cf.stop, cf.wp)
 	case reflect.Uint32:
-		return isSortedO(sb.Cast[uint32](slc))
+		sortI(sb.Cast[uint32](slc), nil, cf.lm, cf.mg, cf.stop, cf.wp)
 	case reflect.Uint64:
-		return isSortedO(sb.Cast[uint64](slc))
+		sortI(sb.Cast[uint64](slc), nil, cf.lm, cf.mg, cf.stop, cf.wp)
 	case reflect.Float32:
-		return isSortedF(sb.Cast[float32](slc))
+		sortF(sb.Cast[float32](slc), nil, cf.lm, cf.mg, cf.nan, cf.stop, cf.wp)
 	case reflect.Float64:
-		return isSortedF(sb.Cast[float64](slc))
+		sortF(sb.Cast[float64](slc), nil, cf.lm, cf.mg, cf.nan, cf.stop, cf.wp)
 	case sliceBias + reflect.Uint8: // [][]byte
-		return isSortedB(sb.Cast[[]byte](slc))
+		if cf.pfx {
+			sortPK(sb.Cast[[]byte](slc), cf.lm, cf.fc, cf.mg, cf.stop, cf.wp)
+		} else {
+			sortB(sb.Cast[[]byte](slc), cf.fc, cf.mg, cf.stop, cf.wp)
+		}
 	case reflect.String:
-		return isSortedO(sb.Cast[string](slc))
+		if cf.pfx {
+			sortPK(sb.Cast[string](slc), cf.lm, cf.fc, cf.mg, cf.stop, cf.wp)
+		} else {
+			sortS(sb.Cast[string](slc), cf.fc, cf.mg, cf.stop, cf.wp)
+		}
+	default:
+		return false
 	}
-	panic("sorty: IsSortedSlice: invalid input type")
+
+	// Descending order reverses the ascending result, see SortSliceDesc & BenchmarkDesc
+	if cf.desc { // reverse ascending result, NaNs go to the other end
+		switch kind {
+		case reflect.Int8, reflect.Uint8:
+			slices.Reverse(sb.Cast[uint8](slc))
+		case reflect.Int16, reflect.Uint16:
+			slices.Reverse(sb.Cast[uint16](slc))
+		case reflect.Int32, reflect.Uint32, reflect.Float32:
+			slices.Reverse(sb.Cast[uint32](slc))
+		case reflect.Int64, reflect.Uint64, reflect.Float64:
+			slices.Reverse(sb.Cast[uint64](slc))
+		case sliceBias + reflect.Uint8: // [][]byte
+			slices.Reverse(sb.Cast[[]byte](slc))
+		case reflect.String:
+			slices.Reverse(sb.Cast[string](slc))
+		}
+	}
+	return true
 }
 
 // SortSlice concurrently sorts ar in ascending order. ar's (underlying) type can be
 //
-//	[]int, []int32, []int64, []uint, []uint32, []uint64,
-//	[]uintptr, []float32, []float64, []string, [][]byte,
+//	[]int, []int8, []int16, []int32, []int64, []uint, []uint8, []uint16,
+//	[]uint32, []uint64, []bool, []uintptr, []float32, []float64, []string, [][]byte,
 //	[]unsafe.Pointer, []*T // for any type T
 //
 // otherwise it panics.
 func SortSlice(ar any) {
+	cf := defConfig()
+	if !sortSlice(ar, &cf) {
+		panic("sorty: SortSlice: invalid input type")
+	}
+}
+
+// SortSliceCtx is like [SortSlice] but stops promptly when ctx is done before sorting
+// finishes, in which case it returns ctx.Err() and ar is left as a permutation of its
+// original members.
+func SortSliceCtx(ctx context.Context, ar any) error {
+	cf := defConfig()
+	done := cancelOn(ctx, &cf)
+	ok := sortSlice(ar, &cf)
+	err := done()
+	if !ok {
+		panic("sorty: SortSliceCtx: invalid input type")
+	}
+	return err
+}
+
+// SortSliceDesc concurrently sorts ar in descending order. ar's (underlying) type can be
+//
+//	[]int, []int8, []int16, []int32, []int64, []uint, []uint8, []uint16,
+//	[]uint32, []uint64, []bool, []uintptr, []float32, []float64, []string, [][]byte,
+//	[]unsafe.Pointer, []*T // for any type T
+//
+// otherwise it panics. NaNs end up at the start with [NaNlarge] and at the end with
+// [NaNsmall], consistent with [NaNoption]. ar is sorted in ascending order by the same
+// kernels as [SortSlice] and then reversed in one extra O(n) pass, which is under 1% of
+// sorting time, instead of doubling the kernels with flipped comparisons.
+func SortSliceDesc(ar any) {
+	cf := defConfig()
+	cf.desc = true
+	if !sortSlice(ar, &cf) {
+		panic("sorty: SortSliceDesc: invalid input type")
+	}
+}
+
+// sortSliceStable concurrently and stably sorts ar in ascending/descending order,
+// using buf as scratch space if possible. Returns false for invalid input types.
+func sortSliceStable(ar, buf any, cf *config) bool {
 	slc, kind := extractSK(ar)
+	bs, n := scratchOf(ar, buf), int(slc.Len)
+	switch kind {
+	case reflect.Float32:
+		ss := sb.Cast[float32](slc)
+		sortStableF(ss, scratch[float32](n, bs), cf.lm, cf.mg, cf.nan, cf.wp)
+		if cf.desc {
+			reverseStable(ss, eqF[float32])
+		}
+	case reflect.Float64:
+		ss := sb.Cast[float64](slc)
+		sortStableF(ss, scratch[float64](n, bs), cf.lm, cf.mg, cf.nan, cf.wp)
+		if cf.desc {
+			reverseStable(ss, eqF[float64])
+		}
+	case sliceBias + reflect.Uint8: // [][]byte
+		cmp := bytes.Compare
+		if cf.desc {
+			cmp = reverseCmp(cmp)
+		}
+		sortStableC(sb.Cast[[]byte](slc), scratch[[]byte](n, bs), cmp, cf.fc, cf.mg, cf.wp)
+	default:
+		// equal integers, pointers or strings are indistinguishable
+		return sortSlice(ar, cf)
+	}
+	return true
+}
+
+// SortSliceStable concurrently sorts ar in ascending order, keeping relative order of
+// equal members. ar's (underlying) type can be
+//
+//	[]int, []int8, []int16, []int32, []int64, []uint, []uint8, []uint16,
+//	[]uint32, []uint64, []bool, []uintptr, []float32, []float64, []string, [][]byte,
+//	[]unsafe.Pointer, []*T // for any type T
+//
+// otherwise it panics. buf is optional scratch space with the same type as ar and
+// length ≥ (len(ar)+1)/2, otherwise (for example nil) a scratch space is allocated
+// if needed. Equal integers, pointers or strings are indistinguishable, so they are
+// sorted in-place like [SortSlice] without scratch space.
+func SortSliceStable(ar, buf any) {
+	cf := defConfig()
+	if !sortSliceStable(ar, buf, &cf) {
+		panic("sorty: SortSliceStable: invalid input type")
+	}
+}
+
+// sortSliceRadix radix sorts long integer & float slices in ascending/descending order
+// using buf if it has ar's exact type, multikey quicksorts []string & [][]byte, and
+// sorts other types with sortSlice. That includes slices of pointers, as radix sort
+// would keep them in scratch space of integers, hidden from garbage collector.
+// Returns false for invalid input types.
+func sortSliceRadix(ar, buf any, cf *config) bool {
+	ta := reflect.TypeOf(ar)
+	if ta == nil || ta.Kind() != reflect.Slice {
+		return false
+	}
+	if k := ta.Elem().Kind(); k == reflect.Pointer || k == reflect.UnsafePointer {
+		return sortSlice(ar, cf)
+	}
+	slc, kind := extractSK(ar)
+	var bs sb.InSlice
+	if reflect.TypeOf(buf) == ta {
+		bs, _ = extractSK(buf)
+	}
+	n := int(slc.Len)
 	switch kind {
 	case reflect.Int32:
-		sortI(sb.Cast[int32](slc))
+		sortI(sb.Cast[int32](slc), radixBuf[int32](n, bs), cf.lm, cf.mg, cf.stop, cf.wp)
 	case reflect.Int64:
-		sortI(sb.Cast[int64](slc))
+		sortI(sb.Cast[int64](slc), radixBuf[int64](n, bs), cf.lm, cf.mg, cf.stop, cf.wp)
 	case reflect.Uint32:
-		sortI(sb.Cast[uint32](slc))
+		sortI(sb.Cast[uint32](slc), radixBuf[uint32](n, bs), cf.lm, cf.mg, cf.stop, cf.wp)
 	case reflect.Uint64:
-		sortI(sb.Cast[uint64](slc))
+		sortI(sb.Cast[uint64](slc), radixBuf[uint64](n, bs), cf.lm, cf.mg, cf.stop, cf.wp)
 	case reflect.Float32:
-		sortF(sb.Cast[float32](slc))
+		sortF(sb.Cast[float32](slc), radixBuf[float32](n, bs), cf.lm, cf.mg, cf.nan,
+			cf.stop, cf.wp)
 	case reflect.Float64:
-		sortF(sb.Cast[float64](slc))
+		sortF(sb.Cast[float64](slc), radixBuf[float64](n, bs), cf.lm, cf.mg, cf.nan,
+			cf.stop, cf.wp)
 	case sliceBias + reflect.Uint8: // [][]byte
-		sortB(sb.Cast[[]byte](slc))
+		sortM(sb.Cast[[]byte](slc), cf.fc, cf.mg, cf.stop, cf.wp)
 	case reflect.String:
-		sortS(sb.Cast[string](slc))
+		sortM(sb.Cast[string](slc), cf.fc, cf.mg, cf.stop, cf.wp)
 	default:
-		panic("sorty: SortSlice: invalid input type")
+		return sortSlice(ar, cf)
+	}
+
+	if cf.desc { // reverse ascending result, NaNs go to the other end
+		switch kind {
+		case reflect.Int32, reflect.Uint32, reflect.Float32:
+			slices.Reverse(sb.Cast[uint32](slc))
+		case reflect.Int64, reflect.Uint64, reflect.Float64:
+			slices.Reverse(sb.Cast[uint64](slc))
+		case sliceBias + reflect.Uint8: // [][]byte
+			slices.Reverse(sb.Cast[[]byte](slc))
+		case reflect.String:
+			slices.Reverse(sb.Cast[string](slc))
+		}
+	}
+	return true
+}
+
+// SortSliceRadix is like [SortSlice] with paths that are faster for long slices. By
+// element type of ar, members are sorted as follows:
+//
+//   - int, int32, int64, uint, uint32, uint64, uintptr, float32, float64: with a
+//     concurrent LSD radix sort if len(ar) ≥ [MinLenRadix], using buf as scratch space.
+//     Floats are radix sorted via order-preserving unsigned keys of their bits, NaNs
+//     are placed according to [NaNoption]. Integers with a small range of values are
+//     counting sorted in-place instead, see [MinLenCount].
+//   - string, []byte: in-place with a concurrent multikey quicksort that partitions on
+//     the byte at current depth, which is faster for members with long common prefixes
+//     like URLs & file paths.
+//   - int8, int16, uint8, uint16, bool, pointers: in-place like [SortSlice].
+//
+// buf is optional scratch space with the same type as ar and length ≥ len(ar),
+// otherwise (for example nil) a scratch space is allocated if needed. buf is only
+// used by radix sort.
+func SortSliceRadix(ar, buf any) {
+	cf := defConfig()
+	if !sortSliceRadix(ar, buf, &cf) {
+		panic("sorty: SortSliceRadix: invalid input type")
+	}
+}
+
+// IsSortedOrdered returns 0 if s is sorted in ascending order, otherwise it
+// returns i > 0 with s[i] < s[i-1]. Unlike [IsSortedSlice], s's type is checked
+// at compile time and can be any slice of ordered type, including
+//
+//	[]int8, []int16, []uint8, []uint16 and named types like []MyInt
+func IsSortedOrdered[S ~[]T, T cmp.Ordered](s S) int {
+	switch kindOf[T]() {
+	case reflect.Float32:
+		return isSortedF(sb.Slice[float32](s), NaNoption)
+	case reflect.Float64:
+		return isSortedF(sb.Slice[float64](s), NaNoption)
+	}
+	return isSortedO(s)
+}
+
+// SortOrdered concurrently sorts s in ascending order. Unlike [SortSlice], s's type
+// is checked at compile time and can be any slice of ordered type, including
+//
+//	[]int8, []int16, []uint8, []uint16 and named types like []MyInt
+func SortOrdered[S ~[]T, T cmp.Ordered](s S) {
+	lm, mg := limits{MaxLenIns, MaxLenRec, 0}, &MaxGor
+	switch kindOf[T]() {
+	case reflect.Int8:
+		sortI(sb.Slice[int8](s), nil, lm, mg, nil, nil)
+	case reflect.Int16:
+		sortI(sb.Slice[int16](s), nil, lm, mg, nil, nil)
+	case reflect.Int32:
+		sortI(sb.Slice[int32](s), nil, lm, mg, nil, nil)
+	case reflect.Int64:
+		sortI(sb.Slice[int64](s), nil, lm, mg, nil, nil)
+	case reflect.Uint8:
+		sortI(sb.Slice[uint8](s), nil, lm, mg, nil, nil)
+	case reflect.Uint16:
+		sortI(sb.Slice[uint16](s), nil, lm, mg, nil, nil)
+	case reflect.Uint32:
+		sortI(sb.Slice[uint32](s), nil, lm, mg, nil, nil)
+	case reflect.Uint64:
+		sortI(sb.Slice[uint64](s), nil, lm, mg, nil, nil)
+	case reflect.Float32:
+		sortF(sb.Slice[float32](s), nil, lm, mg, NaNoption, nil, nil)
+	case reflect.Float64:
+		sortF(sb.Slice[float64](s), nil, lm, mg, NaNoption, nil, nil)
+	case reflect.String:
+		sortS(sb.Slice[string](s), limits{MaxLenInsFC, MaxLenRecFC, 0}, mg, nil, nil)
 	}
 }
@@ -0,0 +1,208 @@
+/*	Copyright (c) 2026, Serhat Şevki Dinçer.
+	This Source Code Form is subject to the terms of the Mozilla Public
+	License, v. 2.0. If a copy of the MPL was not distributed with this
+	file, You can obtain one at http://mozilla.org/MPL/2.0/.
+*/
+
+package sorty
+
+import "context"
+
+// Sorter holds sorting parameters of its methods, so different users of sorty in a
+// program can have their own settings instead of sharing package-level [MaxGor],
+// [NaNoption] and MaxLen* parameters. Create one with [NewSorter]() and adjust its
+// fields. Methods panic if parameters are not feasible. A Sorter can be used by
+// multiple goroutines concurrently.
+type Sorter struct {
+	// MaxGor is the maximum number of goroutines (including caller) that can be
+	// concurrently used for sorting per method call. It can be changed live, even
+	// during ongoing calls. Must be in [1,4096].
+	MaxGor uint64
+
+	// NaNoption determines how NaNs are handled, see package-level [NaNoption].
+	NaNoption FloatOption
+
+	// Descending makes methods sort and check in descending order. Sorting runs the
+	// ascending kernels and then reverses the result in one extra O(n) pass, which
+	// is under 1% of sorting time, see [SortSliceDesc].
+	Descending bool
+
+	// PrefixKeys makes SortSlice methods sort []string & [][]byte primarily on
+	// big-endian 8-byte prefixes of members cached in a parallel []uint64, with full
+	// comparisons only on prefix ties. This is faster when prefixes mostly differ.
+	PrefixKeys bool
+
+	// Maximum slice lengths for insertion sort and recursion, see [MaxLenIns],
+	// [MaxLenInsFC], [MaxLenRec] and [MaxLenRecFC]. Must satisfy
+	// MaxLenRec > 2*MaxLenIns > 16 and MaxLenRecFC > 2*MaxLenInsFC > 16.
+	MaxLenIns, MaxLenInsFC, MaxLenRec, MaxLenRecFC int
+
+	// Pool, if not nil, runs concurrent work of all methods on its workers.
+	Pool *Pool
+}
+
+// NewSorter returns a Sorter with current package-level [MaxGor], [NaNoption]
+// and MaxLen* parameters that sorts in ascending order.
+func NewSorter() *Sorter {
+	return &Sorter{MaxGor, NaNoption, false, false, MaxLenIns, MaxLenInsFC, MaxLenRec,
+		MaxLenRecFC, nil}
+}
+
+// config returns parameters of so, panics if they are not feasible
+func (so *Sorter) config() config {
+	if !(4097 > so.MaxGor && so.MaxGor > 0 && NaNsmall <= so.NaNoption &&
+		so.NaNoption <= NaNlarge && so.MaxLenRec > 2*so.MaxLenIns && so.MaxLenIns >
+		2*nsShort && so.MaxLenRecFC > 2*so.MaxLenInsFC && so.MaxLenInsFC > 2*nsShort) {
+		panic("sorty: check your Sorter values")
+	}
+	return config{limits{so.MaxLenIns, so.MaxLenRec, 0}, limits{so.MaxLenInsFC,
+		so.MaxLenRecFC, 0}, &so.MaxGor, so.NaNoption, so.Descending, so.PrefixKeys, nil, so.Pool}
+}
+
+// IsSortedSlice is like [IsSortedSlice]() with so's parameters.
+func (so *Sorter) IsSortedSlice(ar any) int {
+	cf := so.config()
+	i := isSortedSlice(ar, &cf)
+	if i < 0 {
+		panic("sorty: Sorter.IsSortedSlice: invalid input type")
+	}
+	return i
+}
+
+// SortSlice is like [SortSlice]() with so's parameters.
+func (so *Sorter) SortSlice(ar any) {
+	cf := so.config()
+	if !sortSlice(ar, &cf) {
+		panic("sorty: Sorter.SortSlice: invalid input type")
+	}
+}
+
+// SortSliceCtx is like [SortSliceCtx]() with so's parameters.
+func (so *Sorter) SortSliceCtx(ctx context.Context, ar any) error {
+	cf := so.config()
+	done := cancelOn(ctx, &cf)
+	ok := sortSlice(ar, &cf)
+	err := done()
+	if !ok {
+		panic("sorty: Sorter.SortSliceCtx: invalid input type")
+	}
+	return err
+}
+
+// IsSortedLen is like [IsSortedLen]() with so's parameters.
+func (so *Sorter) IsSortedLen(ar any) int {
+	cf := so.config()
+	i := isSortedLen(ar, &cf)
+	if i < 0 {
+		panic("sorty: Sorter.IsSortedLen: invalid input type")
+	}
+	return i
+}
+
+// SortLen is like [SortLen]() with so's parameters.
+func (so *Sorter) SortLen(ar any) {
+	cf := so.config()
+	if !sortLen(ar, &cf) {
+		panic("sorty: Sorter.SortLen: invalid input type")
+	}
+}
+
+// SortLenCtx is like [SortLenCtx]() with so's parameters.
+func (so *Sorter) SortLenCtx(ctx context.Context, ar any) error {
+	cf := so.config()
+	done := cancelOn(ctx, &cf)
+	ok := sortLen(ar, &cf)
+	err := done()
+	if !ok {
+		panic("sorty: Sorter.SortLenCtx: invalid input type")
+	}
+	return err
+}
+
+// SortSliceStable is like [SortSliceStable]() with so's parameters.
+func (so *Sorter) SortSliceStable(ar, buf any) {
+	cf := so.config()
+	if !sortSliceStable(ar, buf, &cf) {
+		panic("sorty: Sorter.SortSliceStable: invalid input type")
+	}
+}
+
+// SortSliceRadix is like [SortSliceRadix]() with so's parameters.
+func (so *Sorter) SortSliceRadix(ar, buf any) {
+	cf := so.config()
+	if !sortSliceRadix(ar, buf, &cf) {
+		panic("sorty: Sorter.SortSliceRadix: invalid input type")
+	}
+}
+
+// SortLenStable is like [SortLenStable]() with so's parameters.
+func (so *Sorter) SortLenStable(ar, buf any) {
+	cf := so.config()
+	if !sortLenStable(ar, buf, &cf) {
+		panic("sorty: Sorter.SortLenStable: invalid input type")
+	}
+}
+
+// Argsort is like [Argsort]() with so's parameters.
+func (so *Sorter) Argsort(ar any) []int {
+	cf := so.config()
+	perm, ok := argsort(ar, false, &cf)
+	if !ok {
+		panic("sorty: Sorter.Argsort: invalid input type")
+	}
+	return perm
+}
+
+// ArgsortStable is like [ArgsortStable]() with so's parameters.
+func (so *Sorter) ArgsortStable(ar any) []int {
+	cf := so.config()
+	perm, ok := argsort(ar, true, &cf)
+	if !ok {
+		panic("sorty: Sorter.ArgsortStable: invalid input type")
+	}
+	return perm
+}
+
+// IsSorted is like [IsSorted]() with so's parameters.
+func (so *Sorter) IsSorted(n int, lsw Lesswap) int {
+	if so.config().desc {
+		lsw = reverseLsw(lsw)
+	}
+	return IsSorted(n, lsw)
+}
+
+// Sort is like [Sort]() with so's parameters.
+func (so *Sorter) Sort(n int, lsw Lesswap) {
+	cf := so.config()
+	if cf.desc {
+		lsw = reverseLsw(lsw)
+	}
+	sortLesswap(n, lsw, cf.fc, cf.mg, cf.stop, cf.wp)
+}
+
+// SortCtx is like [SortCtx]() with so's parameters.
+func (so *Sorter) SortCtx(ctx context.Context, n int, lsw Lesswap) error {
+	cf := so.config()
+	if cf.desc {
+		lsw = reverseLsw(lsw)
+	}
+	done := cancelOn(ctx, &cf)
+	sortLesswap(n, lsw, cf.fc, cf.mg, cf.stop, cf.wp)
+	return done()
+}
+
+// SortStable is like [SortStable]() with so's parameters.
+func (so *Sorter) SortStable(n int, lsw Lesswap) {
+	cf := so.config()
+	if cf.desc {
+		lsw = reverseLsw(lsw)
+	}
+	sortStable(n, lsw, cf.fc, cf.mg, cf.wp)
+}
+
+// reverseLsw returns a Lesswap with reversed comparison
+func reverseLsw(lsw Lesswap) Lesswap {
+	return func(i, k, r, s int) bool {
+		return lsw(k, i, r, s)
+	}
+}
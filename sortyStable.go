@@ -0,0 +1,328 @@
+/*	Copyright (c) 2026, Serhat Şevki Dinçer.
+	This Source Code Form is subject to the terms of the Mozilla Public
+	License, v. 2.0. If a copy of the MPL was not distributed with this
+	file, You can obtain one at http://mozilla.org/MPL/2.0/.
+*/
+
+package sorty
+
+import (
+	"cmp"
+	"reflect"
+	"sync/atomic"
+
+	sb "github.com/jfcg/sixb/v2"
+)
+
+// new-goroutine task runner
+func gRun(fn func(int), i int, sv *syncVar) {
+	defer finish(sv)
+	if !stopped(sv) {
+		fn(i)
+	}
+}
+
+// runCon runs fn(0), .., fn(n-1) concurrently, caller runs fn(0) and the calls that
+// exceed process-wide goroutine budget. Assumes n ≥ 1.
+func runCon(fn func(int), n int, sv *syncVar, wp *Pool) {
+	sv.glob = atomic.LoadUint64(&globalMax) != 0
+	r := int(reserve(sv, uint64(n-1)))
+	sv.nGor = uint64(r + 1) // number of goroutines including this
+	defer collect(sv)
+
+	for i := n - 1; i >= n-r; i-- {
+		spawn3(wp, gRun, fn, i, sv)
+	}
+	for i := range n - r {
+		if !stopped(sv) {
+			fn(i)
+		}
+	}
+}
+
+// stableParts returns number of parts a collection of length n is split into
+// for concurrent stable sorting, at most *mg parts, each longer than lm.rec.
+// Returns 1 if process-wide goroutine budget is exhausted.
+func stableParts(n int, lm limits, mg *uint64) int {
+	if globalFull() {
+		return 1
+	}
+	p := n / (lm.rec + 1)
+	if m := *mg; uint64(p) > m {
+		p = int(m)
+	}
+	return p
+}
+
+// stableCon splits a collection of length n into p ≥ 2 consecutive parts, sorts
+// them concurrently via srt(lo,hi) and then merges adjacent sorted parts
+// [lo,m) & [m,hi) concurrently via mrg(lo,m,hi) in rounds, until one part is left.
+func stableCon(n, p int, wp *Pool, srt func(lo, hi int), mrg func(lo, m, hi int)) {
+	b := make([]int, p+1) // part boundaries
+	for i := range b {
+		b[i] = i * n / p
+	}
+
+	// create channel only when concurrent sorting
+	sv := syncVar{done: make(chan int), stop: new(uint32)}
+	runCon(func(i int) { srt(b[i], b[i+1]) }, p, &sv, wp)
+
+	for p > 1 {
+		runCon(func(i int) { mrg(b[2*i], b[2*i+1], b[2*i+2]) }, p>>1, &sv, wp)
+
+		k := 1 // merged parts' boundaries
+		for i := 2; i <= p; i += 2 {
+			b[k] = b[i]
+			k++
+		}
+		if p&1 != 0 { // odd part left as is
+			b[k] = b[p]
+			k++
+		}
+		p = k - 1
+	}
+}
+
+// mergeO stably merges sorted ar[:m] & ar[m:] using buf for the shorter one,
+// assumes 0 < m < len(ar) and len(buf) ≥ min(m, len(ar)-m)
+func mergeO[S ~[]T, T cmp.Ordered](ar S, m int, buf S) {
+	if !(ar[m] < ar[m-1]) { // already in order?
+		return
+	}
+	if m <= len(ar)-m { // merge forward
+		b := buf[:copy(buf, ar[:m])]
+		i, k, j := 0, m, 0
+		for i < len(b) && k < len(ar) {
+			if ar[k] < b[i] { // take left on equality
+				ar[j] = ar[k]
+				k++
+			} else {
+				ar[j] = b[i]
+				i++
+			}
+			j++
+		}
+		copy(ar[j:], b[i:]) // rest of the right run is in place
+		return
+	}
+	// merge backward
+	b := buf[:copy(buf, ar[m:])]
+	i, k, j := len(b)-1, m-1, len(ar)-1
+	for i >= 0 && k >= 0 {
+		if b[i] < ar[k] { // take right on equality
+			ar[j] = ar[k]
+			k--
+		} else {
+			ar[j] = b[i]
+			i--
+		}
+		j--
+	}
+	copy(ar, b[:i+1]) // rest of the left run is in place
+}
+
+// stableO stably sorts ar using buf with len(buf) ≥ len(ar)/2, recursive
+func stableO[S ~[]T, T cmp.Ordered](ar, buf S, ins int) {
+	if len(ar) <= ins {
+		insertionO(ar)
+		return
+	}
+	m := len(ar) >> 1
+	stableO(ar[:m], buf, ins)
+	stableO(ar[m:], buf, ins)
+	mergeO(ar, m, buf)
+}
+
+// mergeC is like mergeO with comparator
+func mergeC[S ~[]T, T any](ar S, m int, buf S, cmp func(a, b T) int) {
+	if cmp(ar[m], ar[m-1]) >= 0 { // already in order?
+		return
+	}
+	if m <= len(ar)-m { // merge forward
+		b := buf[:copy(buf, ar[:m])]
+		i, k, j := 0, m, 0
+		for i < len(b) && k < len(ar) {
+			if cmp(ar[k], b[i]) < 0 { // take left on equality
+				ar[j] = ar[k]
+				k++
+			} else {
+				ar[j] = b[i]
+				i++
+			}
+			j++
+		}
+		copy(ar[j:], b[i:]) // rest of the right run is in place
+		return
+	}
+	// merge backward
+	b := buf[:copy(buf, ar[m:])]
+	i, k, j := len(b)-1, m-1, len(ar)-1
+	for i >= 0 && k >= 0 {
+		if cmp(b[i], ar[k]) < 0 { // take right on equality
+			ar[j] = ar[k]
+			k--
+		} else {
+			ar[j] = b[i]
+			i--
+		}
+		j--
+	}
+	copy(ar, b[:i+1]) // rest of the left run is in place
+}
+
+// stableC is like stableO with comparator
+func stableC[S ~[]T, T any](ar, buf S, cmp func(a, b T) int, ins int) {
+	if len(ar) <= ins {
+		insertionC(ar, cmp)
+		return
+	}
+	m := len(ar) >> 1
+	stableC(ar[:m], buf, cmp, ins)
+	stableC(ar[m:], buf, cmp, ins)
+	mergeC(ar, m, buf, cmp)
+}
+
+// sortStableO concurrently and stably sorts ar in ascending order using buf
+// with len(buf) ≥ (len(ar)+1)/2. Each part & merge uses its own region of buf.
+func sortStableO[S ~[]T, T cmp.Ordered](ar, buf S, lm limits, mg *uint64, wp *Pool) {
+	p := stableParts(len(ar), lm, mg)
+	if p < 2 {
+		stableO(ar, buf, lm.ins)
+		return
+	}
+	stableCon(len(ar), p, wp, func(lo, hi int) {
+		stableO(ar[lo:hi], buf[lo>>1:], lm.ins)
+	}, func(lo, m, hi int) {
+		mergeO(ar[lo:hi], m-lo, buf[lo>>1:])
+	})
+}
+
+// sortStableC is like sortStableO with comparator
+func sortStableC[S ~[]T, T any](ar, buf S, cmp func(a, b T) int, lm limits, mg *uint64,
+	wp *Pool) {
+	p := stableParts(len(ar), lm, mg)
+	if p < 2 {
+		stableC(ar, buf, cmp, lm.ins)
+		return
+	}
+	stableCon(len(ar), p, wp, func(lo, hi int) {
+		stableC(ar[lo:hi], buf[lo>>1:], cmp, lm.ins)
+	}, func(lo, m, hi int) {
+		mergeC(ar[lo:hi], m-lo, buf[lo>>1:], cmp)
+	})
+}
+
+// partStable stably moves members of ar satisfying first() to the start and returns
+// their number. Uses buf with len(buf) ≥ (len(ar)+1)/2.
+func partStable[S ~[]T, T any](ar, buf S, first func(T) bool) int {
+	k := 0
+	for _, x := range ar {
+		if first(x) {
+			k++
+		}
+	}
+	if k == 0 || k == len(ar) {
+		return k
+	}
+
+	if len(ar)-k <= len(buf) { // forward: compact firsts, save others
+		i, j := 0, 0
+		for _, x := range ar {
+			if first(x) {
+				ar[i] = x
+				i++
+			} else {
+				buf[j] = x
+				j++
+			}
+		}
+		copy(ar[k:], buf[:j])
+		return k
+	}
+	// backward: compact others, save firsts
+	i, j := len(ar)-1, k-1
+	for h := i; h >= 0; h-- {
+		if x := ar[h]; first(x) {
+			buf[j] = x
+			j--
+		} else {
+			ar[i] = x
+			i--
+		}
+	}
+	copy(ar, buf[:k])
+	return k
+}
+
+// sortStableF concurrently and stably sorts ar in ascending order, nan option is
+// taken into account. Uses buf with len(buf) ≥ (len(ar)+1)/2.
+func sortStableF[S ~[]T, T sb.Float](ar, buf S, lm limits, mg *uint64, nan FloatOption,
+	wp *Pool) {
+	if nan == NaNlarge { // move NaNs to the end
+		k := partStable(ar, buf, func(x T) bool { return x == x })
+		ar = ar[:k]
+	} else if nan == NaNsmall { // move NaNs to the start
+		k := partStable(ar, buf, func(x T) bool { return x != x })
+		ar = ar[k:]
+	}
+	sortStableO(ar, buf, lm, mg, wp)
+}
+
+// reverseStable reverses ascending sorted ar into descending order while keeping
+// relative order of equal members
+func reverseStable[S ~[]T, T any](ar S, eq func(a, b T) bool) {
+	for l, h := 0, len(ar)-1; l < h; l, h = l+1, h-1 {
+		ar[l], ar[h] = ar[h], ar[l]
+	}
+	for l := 0; l < len(ar); { // reverse each run of equal members
+		h := l + 1
+		for h < len(ar) && eq(ar[l], ar[h]) {
+			h++
+		}
+		for i, k := l, h-1; i < k; i, k = i+1, k-1 {
+			ar[i], ar[k] = ar[k], ar[i]
+		}
+		l = h
+	}
+}
+
+// eqF returns true if a & b are equal or both NaNs
+func eqF[T sb.Float](a, b T) bool {
+	return a == b || a != a && b != b
+}
+
+// cmpLen compares a & b by length
+func cmpLen[T hasLen](a, b T) int {
+	return len(a) - len(b)
+}
+
+// scratch returns buf as []T if it is long enough for stably sorting a slice of
+// length n, otherwise it returns a new buffer.
+func scratch[T any](n int, buf sb.InSlice) []T {
+	n = (n + 1) >> 1
+	if buf.Len >= uint(n) {
+		return sb.Cast[T](buf)[:n]
+	}
+	return make([]T, n)
+}
+
+// reverseCmp returns a comparator with reversed order
+func reverseCmp[T any](cmp func(a, b T) int) func(a, b T) int {
+	return func(a, b T) int {
+		return cmp(b, a)
+	}
+}
+
+// scratchOf returns buf's underlying slice if it has the same element type as ar,
+// otherwise an empty slice. Element types with different layouts or pointers must
+// not share scratch space.
+func scratchOf(ar, buf any) (slc sb.InSlice) {
+	if buf == nil {
+		return
+	}
+	ta, tb := reflect.TypeOf(ar), reflect.TypeOf(buf)
+	if ta.Kind() == reflect.Slice && tb.Kind() == reflect.Slice && ta.Elem() == tb.Elem() {
+		slc, _ = extractSK(buf)
+	}
+	return
+}
@@ -8,7 +8,10 @@ package sorty
 
 import (
 	"bytes"
+	"cmp"
+	"math"
 	"reflect"
+	"runtime"
 	"slices"
 	"sort"
 	"testing"
@@ -373,6 +376,18 @@ func medianCpstCompare(testName string, prepare func([]uint32) any,
 }
 
 var stNames = [4]string{"sorty-1", "sorty-2", "sorty-3", "sorty-4"}
+var pkNames = [4]string{"prefix-1", "prefix-2", "prefix-3", "prefix-4"}
+
+// returns name & SortSlice method of a Sorter with current MaxGor for
+// optional prefix keys mode
+func pkSorter(pfx bool) (string, func(any)) {
+	so := NewSorter()
+	so.PrefixKeys = pfx
+	if pfx {
+		return pkNames[MaxGor-1], so.SortSlice
+	}
+	return stNames[MaxGor-1], so.SortSlice
+}
 
 // return sum of sortU4() durations for 1..maxMaxGor goroutines
 // optionally compare with standard sort.Slice
@@ -421,11 +436,12 @@ func implantS(buf []uint32) any {
 	return sb.Slice[string](ss)
 }
 
-// return sum of sortS() durations for 1..maxMaxGor goroutines
-// optionally compare with standard sort.Slice
-func sumDurS(compStd bool) (sum float64) {
+// return sum of sortS() durations for 1..maxMaxGor goroutines, optionally
+// with prefix keys. optionally compare with standard sort.Slice
+func sumDurS(compStd, pfx bool) (sum float64) {
 	for MaxGor = 1; MaxGor <= maxMaxGor; MaxGor++ {
-		sum += medianCpstCompare(stNames[MaxGor-1], implantS, SortSlice, compStd)
+		name, srt := pkSorter(pfx)
+		sum += medianCpstCompare(name, implantS, srt, compStd)
 	}
 	return
 }
@@ -451,11 +467,12 @@ func implantB(buf []uint32) any {
 	return sb.Slice[[]byte](bs)
 }
 
-// return sum of sortB() durations for 1..maxMaxGor goroutines
-// optionally compare with standard sort.Slice
-func sumDurB(compStd bool) (sum float64) {
+// return sum of sortB() durations for 1..maxMaxGor goroutines, optionally
+// with prefix keys. optionally compare with standard sort.Slice
+func sumDurB(compStd, pfx bool) (sum float64) {
 	for MaxGor = 1; MaxGor <= maxMaxGor; MaxGor++ {
-		sum += medianCpstCompare(stNames[MaxGor-1], implantB, SortSlice, compStd)
+		name, srt := pkSorter(pfx)
+		sum += medianCpstCompare(name, implantB, srt, compStd)
 	}
 	return
 }
@@ -628,3 +645,167 @@ func sumDurLswB(compStd bool) (sum float64) {
 	}
 	return
 }
+
+// cmp.Compare() that considers NaNoption
+func cmpNaN[T cmp.Ordered](a, b T) int {
+	if NaNoption == NaNlarge && (a != a || b != b) {
+		return cmp.Compare(b, a)
+	}
+	return cmp.Compare(a, b)
+}
+
+// sort random s with SortOrdered() for 1..maxMaxGor goroutines
+// compare each result with standard slices.Sort
+func checkOrdered[S ~[]T, T cmp.Ordered](s S) {
+	fillSrc()
+	src := sb.Slice[T](srcBuf)
+	s = s[:min(len(s), len(src))]
+	ref := make(S, len(s))
+
+	for MaxGor = 1; MaxGor <= maxMaxGor; MaxGor++ {
+		copy(s, src)
+		copy(ref, src)
+		SortOrdered(s)
+		slices.SortFunc(ref, cmpNaN[T])
+
+		if IsSortedOrdered(s) != 0 {
+			tsPtr.Fatalf("SortOrdered: not sorted: %T", s)
+		}
+		for i := len(s) - 1; i >= 0; i-- {
+			if a, b := s[i], ref[i]; a != b && (a == a || b == b) { // consider NaNs equal
+				tsPtr.Fatalf("SortOrdered: values mismatch: %T %d", s, i)
+			}
+		}
+	}
+}
+
+// sort random input of length n in descending order with srd() for 1..maxMaxGor
+// goroutines, check with isSorted() and compare each result with reversed result
+// of ascending sort sra(). prepare()'s output type determines the sort type.
+func checkDesc(n int, prepare func([]uint32) any, srd, sra func(any),
+	isSorted func(any) int, cmp func(any, any)) {
+
+	if prepare == nil {
+		prepare = func(buf []uint32) any { return buf }
+	}
+	for MaxGor = 1; MaxGor <= maxMaxGor; MaxGor++ {
+		fillSrc()
+		copy(aaBuf[:n], srcBuf)
+		copy(bbBuf[:n], srcBuf)
+		ar, ap := prepare(aaBuf[:n]), prepare(bbBuf[:n])
+
+		srd(ar)
+		if isSorted(ar) != 0 {
+			_, kind := extractSK(ar)
+			tsPtr.Fatal("not sorted in descending order, kind:", kind)
+		}
+
+		sra(ap)
+		swap := reflect.Swapper(ap)
+		for i, k := 0, reflect.ValueOf(ap).Len()-1; i < k; i, k = i+1, k-1 {
+			swap(i, k)
+		}
+		cmp(ar, ap)
+	}
+}
+
+// stably sort copies of src with srt() for 1..maxMaxGor goroutines, compare each
+// result with standard slices.SortStableFunc. same() tells identical members apart.
+func checkStable[T any](src []T, srt func(any), cmp func(a, b T) int,
+	same func(a, b T) bool) {
+
+	ar, ref := make([]T, len(src)), make([]T, len(src))
+	copy(ref, src)
+	slices.SortStableFunc(ref, cmp)
+
+	for MaxGor = 1; MaxGor <= maxMaxGor; MaxGor++ {
+		copy(ar, src)
+		srt(ar)
+
+		for i := len(ar) - 1; i >= 0; i-- {
+			if !same(ar[i], ref[i]) {
+				tsPtr.Fatalf("stable sort mismatch: %T %d", ar, i)
+			}
+		}
+	}
+}
+
+// random floats with many equal members, signed zeros and NaNs with payloads
+func stableFloats(n int) []float64 {
+	fillSrc()
+	fs := make([]float64, n)
+	for i, u := range srcBuf[:n] {
+		switch x := float64(u&63) - 32; {
+		case u>>6&15 == 0:
+			fs[i] = math.Float64frombits(0x7ff8<<48 | uint64(i)<<29) // survives float32 conversion
+		case x == 0 && u>>10&1 == 0:
+			fs[i] = math.Copysign(0, -1)
+		default:
+			fs[i] = x
+		}
+	}
+	return fs
+}
+
+// check that perm is a permutation that sorts ar via cmp(), with ascending
+// indices for equal members if stable
+func checkArgsort[T any](ar []T, perm []int, cmp func(a, b T) int, stable bool) {
+	if len(perm) != len(ar) {
+		tsPtr.Fatal("Argsort: wrong length")
+	}
+	seen := make([]bool, len(ar))
+	for i, p := range perm {
+		if seen[p] {
+			tsPtr.Fatal("Argsort: not a permutation")
+		}
+		seen[p] = true
+
+		if i == 0 {
+			continue
+		}
+		c := cmp(ar[perm[i-1]], ar[p])
+		if c > 0 || c == 0 && stable && perm[i-1] > p {
+			tsPtr.Fatalf("Argsort: wrong order: %T %d", ar, i)
+		}
+	}
+}
+
+// catchSorty returns original value of srt's panic, or nil if it is a *Panic without
+// stack or with single-goroutine sorting, when the panic cannot cross a goroutine
+func catchSorty(srt func()) (v any) {
+	defer func() {
+		v = recover()
+		if p, ok := v.(*Panic); ok {
+			v = nil
+			if MaxGor > 1 && len(p.Stack) > 0 {
+				v = p.Value
+			}
+		}
+	}()
+	srt()
+	return
+}
+
+// wait for spawned goroutines to exit, return true if there are no more than n
+func settled(n int) bool {
+	for i := 0; i < 100; i++ {
+		if runtime.NumGoroutine() <= n {
+			return true
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return false
+}
+
+// Lesswap on ar
+func lswU4(ar []uint32) Lesswap {
+	return func(i, k, r, s int) bool {
+		if ar[i] < ar[k] {
+			if r != s {
+				ar[r], ar[s] = ar[s], ar[r]
+			}
+			return true
+		}
+		return false
+	}
+}
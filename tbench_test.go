@@ -2,6 +2,7 @@ package sorty
 
 import (
 	"os"
+	"slices"
 	"testing"
 
 	"github.com/jfcg/sixb/v2"
@@ -25,10 +26,53 @@ func BenchmarkSortB(b *testing.B) {
 			}
 		}
 		b.StartTimer()
-		sortB(slc)
+		sortB(slc, limits{MaxLenInsFC, MaxLenRecFC, 0}, &MaxGor, nil, nil)
 		b.StopTimer()
 	}
 	if isSortedB(slc) != 0 {
 		b.Fatal("sortB error")
 	}
 }
+
+// sort slices just above concurrency threshold with & without a Pool
+func BenchmarkPool(b *testing.B) {
+	src := make([]uint32, 2*(MaxLenRec+1)+64)
+	for i := range src {
+		src[i] = uint32(i*7919) ^ 0x5bd1e995
+	}
+	ar := make([]uint32, len(src))
+	wp := NewPool(int(MaxGor))
+	defer wp.Close()
+
+	for _, srt := range [...]struct {
+		name string
+		fn   func(any)
+	}{{"SortSlice", SortSlice}, {"Pool", wp.SortSlice}} {
+		b.Run(srt.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for q := 0; q < b.N; q++ {
+				copy(ar, src)
+				srt.fn(ar)
+			}
+		})
+	}
+}
+
+// compare ascending & descending sorts with the reversal pass of descending sorts
+func BenchmarkDesc(b *testing.B) {
+	fillSrc()
+	ar := make([]uint32, 1<<20)
+
+	for _, srt := range [...]struct {
+		name string
+		fn   func()
+	}{{"SortSlice", func() { copy(ar, srcBuf); SortSlice(ar) }},
+		{"SortSliceDesc", func() { copy(ar, srcBuf); SortSliceDesc(ar) }},
+		{"Reverse", func() { slices.Reverse(ar) }}} {
+		b.Run(srt.name, func(b *testing.B) {
+			for q := 0; q < b.N; q++ {
+				srt.fn()
+			}
+		})
+	}
+}
@@ -9,9 +9,18 @@
 package sorty
 
 import (
+	"bytes"
+	"cmp"
+	"context"
 	"fmt"
+	"math"
+	"runtime"
+	"slices"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
+	"unsafe"
 
 	"github.com/jfcg/sixb/v2"
 )
@@ -106,7 +115,8 @@ func TestString(t *testing.T) {
 
 	medianCpstCompare("sort.Slice", implantS, stdSort, true)
 	medianCpstCompare("slices.Sort", implantS, stdSlice, false)
-	sumDurS(true) // sorty
+	sumDurS(true, false) // sorty
+	sumDurS(true, true)  // sorty with prefix keys
 	sumDurLswS(true)
 }
 
@@ -117,7 +127,8 @@ func TestByteSlice(t *testing.T) {
 
 	medianCpstCompare("sort.Slice", implantB, stdSort, true)
 	medianCpstCompare("slices.Sort", implantB, stdSlice, false)
-	sumDurB(true) // sorty
+	sumDurB(true, false) // sorty
+	sumDurB(true, true)  // sorty with prefix keys
 	sumDurLswB(true)
 }
 
@@ -250,3 +261,1572 @@ func iarlsw(i, k, r, s int) bool {
 	}
 	return false
 }
+
+type (
+	myInt8   int8
+	myUint16 uint16
+	myInt    int
+	myUint   uint
+	myFloat  float64
+	myString string
+)
+
+// test SortOrdered() on named & small types
+// compare each result with standard slices.Sort
+func TestOrdered(t *testing.T) {
+	tsPtr = t
+	const n = 1 << 20
+
+	checkOrdered(make([]myInt8, n))
+	checkOrdered(make([]uint8, n))
+	checkOrdered(make([]int16, n))
+	checkOrdered(make([]myUint16, n))
+	checkOrdered(make([]myInt, n))
+	checkOrdered(make([]myUint, n))
+	checkOrdered(make([]uintptr, n))
+	checkOrdered(make([]float32, n))
+	checkOrdered(make([]myFloat, n))
+
+	ss := make([]myString, n)
+	for i := range ss {
+		ss[i] = myString(fmt.Sprint(i * 7919 % n))
+	}
+	SortOrdered(ss)
+	if IsSortedOrdered(ss) != 0 || ss[1] != "1" || ss[n-1] != "999999" {
+		t.Fatal("SortOrdered/IsSortedOrdered does not work")
+	}
+}
+
+// test descending sorts & checks
+// compare each result with reversed ascending sort
+func TestDescending(t *testing.T) {
+	tsPtr = t
+	const n = 1 << 20
+
+	checkDesc(n, nil, SortSliceDesc, SortSlice, IsSortedSliceDesc, compare)
+	checkDesc(n, U4toI8, SortSliceDesc, SortSlice, IsSortedSliceDesc, compare)
+
+	for _, NaNoption = range [...]FloatOption{NaNsmall, NaNlarge} {
+		checkDesc(n, U4toF4, SortSliceDesc, SortSlice, IsSortedSliceDesc, compare)
+		checkDesc(n, U4toF8, SortSliceDesc, SortSlice, IsSortedSliceDesc, compare)
+	}
+
+	checkDesc(n, implantS, SortSliceDesc, SortSlice, IsSortedSliceDesc, compare)
+	checkDesc(n, implantB, SortSliceDesc, SortSlice, IsSortedSliceDesc, compare)
+	checkDesc(n, implantLenS, SortLenDesc, SortLen, IsSortedLenDesc, compareLen)
+	checkDesc(n, implantLenB, SortLenDesc, SortLen, IsSortedLenDesc, compareLen)
+
+	if IsSortedSliceDesc([]int{3, 2, 2, 1}) != 0 || IsSortedSliceDesc([]int{3, 2, 4}) != 2 ||
+		IsSortedLenDesc([]string{"ab", "b", "c", ""}) != 0 || IsSortedLenDesc([]string{"", "a"}) != 1 {
+		t.Fatal("IsSortedSliceDesc/IsSortedLenDesc does not work")
+	}
+}
+
+// test Sorter with various parameters
+// compare each result with standard sort.Slice
+func TestSorter(t *testing.T) {
+	tsPtr = t
+	const n = 1 << 20
+	NaNoption = NaNlarge
+
+	so := NewSorter()
+	so.MaxGor, so.NaNoption = 4, NaNsmall
+	so.MaxLenIns, so.MaxLenInsFC, so.MaxLenRec, so.MaxLenRecFC = 20, 12, 100, 40
+
+	for _, so.Descending = range [...]bool{false, true} {
+		for _, prep := range [...]func([]uint32) any{nil, U4toF4, U4toF8, implantS, implantB} {
+			fillSrc()
+			copy(aaBuf[:n], srcBuf)
+			copy(bbBuf[:n], srcBuf)
+			ar, ap := aaBuf[:n], bbBuf[:n]
+			var arp, app any = ar, ap
+			if prep != nil {
+				arp, app = prep(ar), prep(ap)
+			}
+
+			so.SortSlice(arp)
+			if so.IsSortedSlice(arp) != 0 {
+				t.Fatal("Sorter.SortSlice does not work")
+			}
+
+			NaNoption = NaNsmall // so.NaNoption for reference
+			if so.Descending {
+				SortSliceDesc(app)
+			} else {
+				stdSort(app)
+			}
+			NaNoption = NaNlarge
+			compare(arp, app)
+		}
+
+		lens := implantLenS(aaBuf[:n])
+		so.SortLen(lens)
+		if so.IsSortedLen(lens) != 0 || (IsSortedLenDesc(lens) == 0) != so.Descending {
+			t.Fatal("Sorter.SortLen does not work")
+		}
+
+		so.Sort(len(iArr), iarlsw)
+		if so.IsSorted(len(iArr), iarlsw) != 0 || (IsSortedSliceDesc(iArr) == 0) != so.Descending {
+			t.Fatal("Sorter.Sort does not work")
+		}
+	}
+
+	if NaNoption != NaNlarge {
+		t.Fatal("Sorter changed NaNoption")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("infeasible Sorter must panic")
+		}
+	}()
+	so.MaxLenRec = 2 * so.MaxLenIns
+	so.SortSlice(iArr)
+}
+
+// test stable sorts on inputs with many equal members
+// compare each result with standard slices.SortStableFunc
+func TestStable(t *testing.T) {
+	tsPtr = t
+	const n = 1 << 18
+
+	f8 := stableFloats(n)
+	f4 := make([]float32, n)
+	for i, x := range f8 {
+		f4[i] = float32(x)
+	}
+	sameF8 := func(a, b float64) bool { return math.Float64bits(a) == math.Float64bits(b) }
+	sameF4 := func(a, b float32) bool { return math.Float32bits(a) == math.Float32bits(b) }
+	buf4 := make([]float32, (n+1)/2) // exact scratch size
+
+	for _, NaNoption = range [...]FloatOption{NaNsmall, NaNlarge} {
+		checkStable(f8, func(ar any) { SortSliceStable(ar, nil) }, cmpNaN[float64], sameF8)
+		checkStable(f4, func(ar any) { SortSliceStable(ar, buf4) }, cmpNaN[float32], sameF4)
+	}
+
+	bs := make([][]byte, n)
+	ls := make([]string, n)
+	for i, u := range srcBuf[:n] {
+		bs[i] = []byte(fmt.Sprint(u % 1000)) // distinct underlying arrays
+		ls[i] = fmt.Sprint(u % 100000)
+	}
+	sameB := func(a, b []byte) bool { return unsafe.SliceData(a) == unsafe.SliceData(b) }
+	cmpLenB := func(a, b []byte) int { return len(a) - len(b) }
+	wrongBuf := make([]int, n) // ignored, scratch is allocated
+
+	checkStable(bs, func(ar any) { SortSliceStable(ar, wrongBuf) }, bytes.Compare, sameB)
+	checkStable(bs, func(ar any) { SortLenStable(ar, nil) }, cmpLenB, sameB)
+	checkStable(ls, func(ar any) { SortLenStable(ar, make([]string, n)) },
+		func(a, b string) int { return len(a) - len(b) },
+		func(a, b string) bool { return a == b })
+
+	// scratch of a different [][]U type is ignored & left intact
+	wrongB := make([][]int64, n)
+	checkStable(bs, func(ar any) { SortSliceStable(ar, wrongB) }, bytes.Compare, sameB)
+	checkStable(bs, func(ar any) { SortLenStable(ar, wrongB) }, cmpLenB, sameB)
+	if slices.ContainsFunc(wrongB, func(s []int64) bool { return s != nil }) {
+		t.Fatal("SortSliceStable/SortLenStable wrote into scratch of a different type")
+	}
+
+	// descending with Sorter
+	so := NewSorter()
+	so.MaxGor, so.Descending = 5, true // so.NaNoption = NaNoption
+	checkStable(f8, func(ar any) { so.SortSliceStable(ar, nil) },
+		func(a, b float64) int { return cmpNaN(b, a) }, sameF8)
+	checkStable(bs, func(ar any) { so.SortSliceStable(ar, nil) },
+		func(a, b []byte) int { return bytes.Compare(b, a) }, sameB)
+	checkStable(bs, func(ar any) { so.SortLenStable(ar, nil) },
+		func(a, b []byte) int { return len(b) - len(a) }, sameB)
+
+	is := make([]int, n)
+	for i, u := range srcBuf[:n] {
+		is[i] = int(u % 1000)
+	}
+	SortSliceStable(is, nil)
+	so.SortSliceStable(ls, nil)
+	if IsSortedSlice(is) != 0 || IsSortedSliceDesc(ls) != 0 {
+		t.Fatal("SortSliceStable does not work")
+	}
+}
+
+// test SortStable() on parallel key & index arrays with many equal keys
+// check order of keys and of indices with equal keys
+func TestStableLsw(t *testing.T) {
+	const n = 1 << 17
+	fillSrc()
+	key, idx := make([]uint32, n), make([]int, n)
+
+	lsw := func(i, k, r, s int) bool {
+		if key[i] < key[k] {
+			if r != s {
+				key[r], key[s] = key[s], key[r]
+				idx[r], idx[s] = idx[s], idx[r]
+			}
+			return true
+		}
+		return false
+	}
+	check := func(desc bool) {
+		for i := n - 1; i > 0; i-- {
+			a, b := key[i-1], key[i]
+			if desc {
+				a, b = b, a
+			}
+			if a > b || a == b && idx[i-1] > idx[i] {
+				t.Fatal("SortStable does not work at", i)
+			}
+		}
+	}
+	prep := func(m uint32) {
+		for i, u := range srcBuf[:n] {
+			key[i], idx[i] = u%m, i
+		}
+	}
+
+	for MaxGor = 1; MaxGor <= maxMaxGor; MaxGor++ {
+		for _, m := range [...]uint32{3, 1000, n} {
+			prep(m)
+			SortStable(n, lsw)
+			check(false)
+		}
+	}
+
+	so := NewSorter()
+	so.MaxGor, so.Descending = 5, true
+	prep(1000)
+	so.SortStable(n, lsw)
+	check(true)
+}
+
+// test SortFunc() on a struct slice with many equal keys
+// compare each result with standard slices.SortFunc
+func TestFunc(t *testing.T) {
+	type rec struct {
+		key uint32
+		val float64
+	}
+	const n = 1 << 20
+	cmpRec := func(a, b rec) int { return cmp.Compare(a.key, b.key) }
+
+	fillSrc()
+	src, ar, ap := make([]rec, n), make([]rec, n), make([]rec, n)
+	for i, u := range srcBuf[:n] {
+		src[i] = rec{u % 10000, float64(i)}
+	}
+	copy(ap, src)
+	slices.SortFunc(ap, cmpRec)
+
+	for MaxGor = 1; MaxGor <= maxMaxGor; MaxGor++ {
+		copy(ar, src)
+		SortFunc(ar, cmpRec)
+
+		if IsSortedFunc(ar, cmpRec) != 0 {
+			t.Fatal("SortFunc does not work")
+		}
+		for i := n - 1; i >= 0; i-- {
+			if ar[i].key != ap[i].key {
+				t.Fatal("SortFunc: values mismatch at", i)
+			}
+		}
+	}
+	if IsSortedFunc([]int{1, 3, 2}, cmp.Compare[int]) != 2 {
+		t.Fatal("IsSortedFunc does not work")
+	}
+}
+
+type keyRec struct {
+	id  int
+	i8  int8
+	u   uint
+	f   float32
+	str string
+}
+
+// check that rs is sorted by key() and has all ids
+func checkByKey[K cmp.Ordered](rs []keyRec, key func(keyRec) K) {
+	keys := make([]K, len(rs))
+	seen := make([]bool, len(rs))
+	for i, r := range rs {
+		keys[i] = key(r)
+		if seen[r.id] {
+			tsPtr.Fatal("duplicate member", r.id)
+		}
+		seen[r.id] = true
+	}
+	if i := IsSortedOrdered(keys); i != 0 {
+		tsPtr.Fatalf("SortByKey: not sorted by %T key at %d", keys[0], i)
+	}
+}
+
+// test SortByKey() on struct fields of various types
+func TestByKey(t *testing.T) {
+	tsPtr = t
+	const n = 1 << 20
+	fillSrc()
+	rs := make([]keyRec, n)
+	for i, u := range srcBuf[:n] {
+		rs[i] = keyRec{i, int8(u), uint(u % 5000), sixb.Slice[float32](srcBuf)[i],
+			fmt.Sprint(u % 70000)}
+	}
+
+	for MaxGor = 1; MaxGor <= maxMaxGor; MaxGor++ {
+		for _, NaNoption = range [...]FloatOption{NaNsmall, NaNlarge} {
+			key := func(r keyRec) float32 { return r.f }
+			SortByKey(rs, key)
+			checkByKey(rs, key)
+		}
+		key := func(r keyRec) int8 { return r.i8 }
+		SortByKey(rs, key)
+		checkByKey(rs, key)
+
+		key2 := func(r keyRec) uint { return r.u }
+		SortByKey(rs, key2)
+		checkByKey(rs, key2)
+
+		key3 := func(r keyRec) string { return r.str }
+		SortByKey(rs, key3)
+		checkByKey(rs, key3)
+	}
+}
+
+type myKeys []uint64
+
+// check that each val has its key & all ids
+func checkPairs[K cmp.Ordered](keys []K, vals []keyRec, key func(keyRec) K) {
+	seen := make([]bool, len(vals))
+	for i, v := range vals {
+		if k := key(v); k != keys[i] && (k == k || keys[i] == keys[i]) { // NaNs equal
+			tsPtr.Fatalf("SortPairs: %T key/val mismatch at %d", k, i)
+		}
+		if seen[v.id] {
+			tsPtr.Fatal("SortPairs: duplicate val", v.id)
+		}
+		seen[v.id] = true
+	}
+	if i := IsSortedOrdered(keys); i != 0 {
+		tsPtr.Fatalf("SortPairs: %T keys not sorted at %d", keys[0], i)
+	}
+}
+
+// test SortPairs() on parallel key & val slices
+func TestPairs(t *testing.T) {
+	tsPtr = t
+	const n = 1 << 20
+	fillSrc()
+	vals := make([]keyRec, n)
+	uk, fk, sk := make(myKeys, n), make([]float32, n), make([]string, n)
+	fs := sixb.Slice[float32](srcBuf)
+
+	for MaxGor = 1; MaxGor <= maxMaxGor; MaxGor++ {
+		for i, u := range srcBuf[:n] {
+			vals[i] = keyRec{id: i, u: uint(u % 5000), f: fs[i], str: fmt.Sprint(u % 70000)}
+			uk[i], fk[i], sk[i] = uint64(vals[i].u), vals[i].f, vals[i].str
+		}
+		SortPairs(uk, vals)
+		checkPairs(uk, vals, func(r keyRec) uint64 { return uint64(r.u) })
+
+		for _, NaNoption = range [...]FloatOption{NaNsmall, NaNlarge} {
+			for i, v := range vals {
+				fk[i] = v.f
+			}
+			SortPairs(fk, vals)
+			checkPairs(fk, vals, func(r keyRec) float32 { return r.f })
+		}
+
+		for i, v := range vals {
+			sk[i] = v.str
+		}
+		SortPairs(sk, vals)
+		checkPairs(sk, vals, func(r keyRec) string { return r.str })
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("SortPairs must panic on different lengths")
+		}
+	}()
+	SortPairs(uk, vals[1:])
+}
+
+// test Argsort*() & permutation helpers
+func TestArgsort(t *testing.T) {
+	tsPtr = t
+	const n = 1 << 20
+	fillSrc()
+
+	is := make([]int, n)
+	i2 := make([]int16, n)
+	bs := make([][]byte, n)
+	ss := make([]string, n)
+	for i, u := range srcBuf[:n] {
+		is[i], i2[i] = int(u%10000), int16(u)
+		bs[i] = []byte(fmt.Sprint(u % 1000))
+		ss[i] = string(bs[i])
+	}
+	fs := stableFloats(n)
+	isc := slices.Clone(is)
+
+	for MaxGor = 1; MaxGor <= maxMaxGor; MaxGor++ {
+		checkArgsort(is, Argsort(is), cmp.Compare[int], false)
+		checkArgsort(is, ArgsortStable(is), cmp.Compare[int], true)
+		checkArgsort(i2, Argsort(i2), cmp.Compare[int16], false)
+		checkArgsort(i2, ArgsortStable(i2), cmp.Compare[int16], true)
+		checkArgsort(ss, ArgsortStable(ss), cmp.Compare[string], true)
+		checkArgsort(bs, ArgsortStable(bs), bytes.Compare, true)
+		checkArgsort(bs, Argsort(bs), bytes.Compare, false)
+
+		for _, NaNoption = range [...]FloatOption{NaNsmall, NaNlarge} {
+			checkArgsort(fs, Argsort(fs), cmpNaN[float64], false)
+			checkArgsort(fs, ArgsortStable(fs), cmpNaN[float64], true)
+		}
+
+		cmpLen := func(a, b string) int { return len(a) - len(b) }
+		checkArgsort(ss, ArgsortFunc(ss, cmpLen), cmpLen, false)
+		checkArgsort(ss, ArgsortStableFunc(ss, cmpLen), cmpLen, true)
+	}
+	if !slices.Equal(is, isc) {
+		t.Fatal("Argsort modified its input")
+	}
+
+	so := NewSorter()
+	so.Descending = true
+	desc := func(a, b int) int { return b - a }
+	checkArgsort(is, so.ArgsortStable(is), desc, true)
+	checkArgsort(fs, so.ArgsortStable(fs),
+		func(a, b float64) int { return cmpNaN(b, a) }, true)
+
+	// sort with permutation, invert it
+	perm := Argsort(is)
+	pc := slices.Clone(perm)
+	ApplyPerm(is, perm)
+	if IsSortedSlice(is) != 0 || !slices.Equal(perm, pc) {
+		t.Fatal("ApplyPerm does not work")
+	}
+	InvertPerm(perm)
+	for i, p := range pc {
+		if perm[p] != i {
+			t.Fatal("InvertPerm does not work")
+		}
+	}
+	ApplyPerm(is, perm)
+	if !slices.Equal(is, isc) {
+		t.Fatal("ApplyPerm with inverse does not restore")
+	}
+}
+
+// check ar[:k] against sorted ref and ar's members against ref
+func checkPartial[T cmp.Ordered](ar, ref []T, k int, srt func([]T)) {
+	k = max(min(k, len(ar)), 0)
+	for i := k - 1; i >= 0; i-- {
+		if a, b := ar[i], ref[i]; a != b && (a == a || b == b) { // NaNs equal
+			tsPtr.Fatalf("partial sort: %T mismatch at %d", ar, i)
+		}
+	}
+	srt(ar)
+	for i := len(ar) - 1; i >= 0; i-- {
+		if a, b := ar[i], ref[i]; a != b && (a == a || b == b) {
+			tsPtr.Fatalf("partial sort: %T lost members", ar)
+		}
+	}
+}
+
+// test SortPartial*() for various k
+// compare each result with standard slices.SortFunc
+func TestPartial(t *testing.T) {
+	tsPtr = t
+	const n = 1 << 18
+	fillSrc()
+
+	is := make([]int64, n)
+	i8 := make([]int8, n)
+	ss := make([]string, n)
+	for i, u := range srcBuf[:n] {
+		is[i], i8[i], ss[i] = int64(u%1000), int8(u), fmt.Sprint(u)
+	}
+	fs := stableFloats(n)
+
+	for _, k := range [...]int{-1, 0, 1, 5, 30, 1000, n / 2, n - 1, n, n + 1} {
+		for MaxGor = 1; MaxGor <= maxMaxGor; MaxGor++ {
+			ar, ref := slices.Clone(is), slices.Clone(is)
+			slices.Sort(ref)
+			SortPartial(ar, k)
+			checkPartial(ar, ref, k, slices.Sort)
+
+			a8, r8 := slices.Clone(i8), slices.Clone(i8)
+			slices.Sort(r8)
+			SortPartialOrdered(a8, k)
+			checkPartial(a8, r8, k, slices.Sort)
+			copy(a8, i8)
+			SortPartial(a8, k)
+			checkPartial(a8, r8, k, slices.Sort)
+
+			as, rs := slices.Clone(ss), slices.Clone(ss)
+			slices.Sort(rs)
+			SortPartial(as, k)
+			checkPartial(as, rs, k, slices.Sort)
+
+			// [][]byte with strings as reference
+			bs := make([][]byte, n)
+			for i, s := range ss {
+				bs[i] = []byte(s)
+			}
+			SortPartial(bs, k)
+			for i := range as {
+				as[i] = string(bs[i])
+			}
+			checkPartial(as, rs, k, slices.Sort)
+
+			for _, NaNoption = range [...]FloatOption{NaNsmall, NaNlarge} {
+				af, rf := slices.Clone(fs), slices.Clone(fs)
+				slices.SortFunc(rf, cmpNaN[float64])
+				SortPartial(af, k)
+				checkPartial(af, rf, k, func(s []float64) { slices.SortFunc(s, cmpNaN[float64]) })
+			}
+
+			copy(ar, is)
+			SortPartialLsw(n, k, func(i, k, r, s int) bool {
+				if ar[i] < ar[k] {
+					if r != s {
+						ar[r], ar[s] = ar[s], ar[r]
+					}
+					return true
+				}
+				return false
+			})
+			checkPartial(ar, ref, k, slices.Sort)
+		}
+	}
+}
+
+// check ar[k] against sorted ref and ar[:k] ≤ ar[k] ≤ ar[k+1:]
+func checkSelect[T any](ar, ref []T, k int, cmp func(a, b T) int) {
+	if cmp(ar[k], ref[k]) != 0 {
+		tsPtr.Fatalf("Select: %T mismatch at %d", ar, k)
+	}
+	for i := range ar {
+		if c := cmp(ar[i], ar[k]); i < k && c > 0 || i > k && c < 0 {
+			tsPtr.Fatalf("Select: %T not partitioned at %d", ar, i)
+		}
+	}
+}
+
+// test Select*() for various k
+// compare each result with standard slices.SortFunc
+func TestSelect(t *testing.T) {
+	tsPtr = t
+	const n = 1 << 18
+	fillSrc()
+
+	is := make([]int64, n)
+	i8 := make([]int8, n)
+	ss := make([]string, n)
+	for i, u := range srcBuf[:n] {
+		is[i], i8[i], ss[i] = int64(u%1000), int8(u), fmt.Sprint(u)
+	}
+	fs := stableFloats(n)
+	ri, r8, rs := slices.Clone(is), slices.Clone(i8), slices.Clone(ss)
+	slices.Sort(ri)
+	slices.Sort(r8)
+	slices.Sort(rs)
+
+	for _, k := range [...]int{0, 1, 30, 1000, n / 2, n - 2, n - 1} {
+		ar := slices.Clone(is)
+		Select(ar, k)
+		checkSelect(ar, ri, k, cmp.Compare[int64])
+
+		copy(ar, is)
+		SelectLsw(n, k, func(i, k, r, s int) bool {
+			if ar[i] < ar[k] {
+				if r != s {
+					ar[r], ar[s] = ar[s], ar[r]
+				}
+				return true
+			}
+			return false
+		})
+		checkSelect(ar, ri, k, cmp.Compare[int64])
+
+		a8 := slices.Clone(i8)
+		SelectOrdered(a8, k)
+		checkSelect(a8, r8, k, cmp.Compare[int8])
+		copy(a8, i8)
+		Select(a8, k)
+		checkSelect(a8, r8, k, cmp.Compare[int8])
+
+		as := slices.Clone(ss)
+		Select(as, k)
+		checkSelect(as, rs, k, cmp.Compare[string])
+
+		bs := make([][]byte, n)
+		for i, s := range ss {
+			bs[i] = []byte(s)
+		}
+		Select(bs, k)
+		for i := range as {
+			as[i] = string(bs[i])
+		}
+		checkSelect(as, rs, k, cmp.Compare[string])
+
+		for _, NaNoption = range [...]FloatOption{NaNsmall, NaNlarge} {
+			af, rf := slices.Clone(fs), slices.Clone(fs)
+			slices.SortFunc(rf, cmpNaN[float64])
+			Select(af, k)
+			checkSelect(af, rf, k, cmpNaN[float64])
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Select must panic for k out of range")
+		}
+	}()
+	Select(is, n)
+}
+
+// test SelectMany() & Quantiles()
+// compare each result with standard slices.SortFunc
+func TestSelectMany(t *testing.T) {
+	tsPtr = t
+	const n = 1 << 19
+	fillSrc()
+
+	is := make([]uint32, n)
+	u2 := make([]uint16, n)
+	ss := make([]string, n)
+	for i, u := range srcBuf[:n] {
+		is[i], u2[i], ss[i] = u%5000, uint16(u), fmt.Sprint(u)
+	}
+	fs := stableFloats(n)
+	ri, r2, rs := slices.Clone(is), slices.Clone(u2), slices.Clone(ss)
+	slices.Sort(ri)
+	slices.Sort(r2)
+	slices.Sort(rs)
+	ks := []int{n - 1, 7, 0, n / 2, 7, 1000, n/2 + 1, n - 9, 20000}
+
+	for MaxGor = 1; MaxGor <= maxMaxGor; MaxGor++ {
+		ar := slices.Clone(is)
+		SelectMany(ar, ks)
+		for _, k := range ks {
+			checkSelect(ar, ri, k, cmp.Compare[uint32])
+		}
+
+		a2 := slices.Clone(u2)
+		SelectMany(a2, ks)
+		for _, k := range ks {
+			checkSelect(a2, r2, k, cmp.Compare[uint16])
+		}
+
+		as := slices.Clone(ss)
+		SelectMany(as, ks)
+		for _, k := range ks {
+			checkSelect(as, rs, k, cmp.Compare[string])
+		}
+
+		bs := make([][]byte, n)
+		for i, s := range ss {
+			bs[i] = []byte(s)
+		}
+		SelectMany(bs, ks)
+		for i := range as {
+			as[i] = string(bs[i])
+		}
+		for _, k := range ks {
+			checkSelect(as, rs, k, cmp.Compare[string])
+		}
+
+		for _, NaNoption = range [...]FloatOption{NaNsmall, NaNlarge} {
+			af, rf := slices.Clone(fs), slices.Clone(fs)
+			slices.SortFunc(rf, cmpNaN[float64])
+			SelectMany(af, ks)
+			for _, k := range ks {
+				checkSelect(af, rf, k, cmpNaN[float64])
+			}
+		}
+
+		copy(ar, is)
+		qs := []float64{0, .5, .9, .99, .999, 1}
+		qk := Quantiles(ar, qs)
+		for i, k := range qk {
+			if k != int(math.Round(qs[i]*(n-1))) {
+				t.Fatal("Quantiles: wrong rank")
+			}
+			checkSelect(ar, ri, k, cmp.Compare[uint32])
+		}
+
+		copy(a2, u2)
+		for _, k := range Quantiles(a2, qs) {
+			checkSelect(a2, r2, k, cmp.Compare[uint16])
+		}
+	}
+	if !slices.Equal(ks, []int{n - 1, 7, 0, n / 2, 7, 1000, n/2 + 1, n - 9, 20000}) {
+		t.Fatal("SelectMany modified ranks")
+	}
+
+	if len(Quantiles([]int{}, nil)) != 0 {
+		t.Fatal("Quantiles must return no ranks for no quantiles")
+	}
+	func() {
+		defer func() {
+			if p := recover(); p != "sorty: Quantiles: empty input" {
+				t.Fatal("Quantiles must panic for empty input, got:", p)
+			}
+		}()
+		Quantiles([]int{}, []float64{.5})
+	}()
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Quantiles must panic for q out of range")
+		}
+	}()
+	Quantiles(is, []float64{1.5})
+}
+
+// test SortSliceCtx(), SortLenCtx() & SortCtx()
+// compare each result with standard slices.Sort
+func TestCtx(t *testing.T) {
+	tsPtr = t
+	const n = 1 << 19
+	fillSrc()
+
+	is := slices.Clone(srcBuf[:n])
+	ss := make([]string, n)
+	for i, u := range is {
+		ss[i] = fmt.Sprint(u)
+	}
+	ri, rs := slices.Clone(is), slices.Clone(ss)
+	slices.Sort(ri)
+	slices.Sort(rs)
+	done, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// short input is sorted without checking ctx, so there is no error
+	small := []int{3, 1, 2}
+	if SortSliceCtx(done, small) != nil || !slices.IsSorted(small) {
+		t.Fatal("SortSliceCtx must not return an error for a finished sort")
+	}
+
+	for MaxGor = 1; MaxGor <= maxMaxGor; MaxGor++ {
+		ar := slices.Clone(is)
+		if SortSliceCtx(context.Background(), ar) != nil || !slices.Equal(ar, ri) {
+			t.Fatal("SortSliceCtx does not work")
+		}
+		copy(ar, is)
+		if SortSliceCtx(done, ar) != context.Canceled {
+			t.Fatal("SortSliceCtx must return context.Canceled")
+		}
+		slices.Sort(ar)
+		if !slices.Equal(ar, ri) {
+			t.Fatal("SortSliceCtx must leave a permutation")
+		}
+
+		as := slices.Clone(ss)
+		if SortLenCtx(done, as) != context.Canceled {
+			t.Fatal("SortLenCtx must return context.Canceled")
+		}
+		slices.Sort(as)
+		if !slices.Equal(as, rs) {
+			t.Fatal("SortLenCtx must leave a permutation")
+		}
+
+		// sorting on prefix keys stops too
+		so := NewSorter()
+		so.PrefixKeys = true
+		copy(as, ss)
+		if so.SortSliceCtx(done, as) != context.Canceled {
+			t.Fatal("Sorter.SortSliceCtx must return context.Canceled")
+		}
+		desc := 0
+		for i := 1; i < n; i++ {
+			if as[i] < as[i-1] {
+				desc++
+			}
+		}
+		if desc < n/4 {
+			t.Fatal("sorting on prefix keys must stop", desc)
+		}
+
+		// cancel while sorting, remaining work must be small
+		ctx, cancel := context.WithCancel(context.Background())
+		var calls uint64
+		copy(ar, is)
+		err := SortCtx(ctx, n, func(i, k, r, s int) bool {
+			if atomic.AddUint64(&calls, 1) == 2*n {
+				cancel()
+				time.Sleep(time.Millisecond) // let cancellation take effect
+			}
+			if ar[i] < ar[k] {
+				if r != s {
+					ar[r], ar[s] = ar[s], ar[r]
+				}
+				return true
+			}
+			return false
+		})
+		if err != context.Canceled || calls > 8*n {
+			t.Fatal("SortCtx must stop promptly", calls/n)
+		}
+		slices.Sort(ar)
+		if !slices.Equal(ar, ri) {
+			t.Fatal("SortCtx must leave a permutation")
+		}
+	}
+}
+
+// test panics in Lesswap & comparators propagate to caller, as *Panic only from
+// spawned goroutines
+// compare Lesswap result with standard slices.Sort
+func TestPanic(t *testing.T) {
+	tsPtr = t
+	const n = 1 << 19
+	fillSrc()
+
+	is := slices.Clone(srcBuf[:n])
+	ri := slices.Clone(is)
+	slices.Sort(ri)
+	ngr := runtime.NumGoroutine()
+
+	for MaxGor = 1; MaxGor <= maxMaxGor; MaxGor++ {
+		for _, m := range [...]uint64{1, n / 4, n, 3 * n} {
+			ar := slices.Clone(is)
+			var calls uint64
+			v := catchSorty(func() {
+				Sort(n, func(i, k, r, s int) bool {
+					if atomic.AddUint64(&calls, 1) == m {
+						panic("lsw")
+					}
+					if ar[i] < ar[k] {
+						if r != s {
+							ar[r], ar[s] = ar[s], ar[r]
+						}
+						return true
+					}
+					return false
+				})
+			})
+			if v != "lsw" || !settled(ngr) {
+				t.Fatal("Sort must propagate panic & stop goroutines")
+			}
+			slices.Sort(ar)
+			if !slices.Equal(ar, ri) {
+				t.Fatal("Sort must leave a permutation after panic")
+			}
+
+			copy(ar, is)
+			calls = 0
+			v = catchSorty(func() {
+				SortFunc(ar, func(a, b uint32) int {
+					if atomic.AddUint64(&calls, 1) == m {
+						panic("cmp")
+					}
+					return cmp.Compare(a, b)
+				})
+			})
+			if v != "cmp" || !settled(ngr) {
+				t.Fatal("SortFunc must propagate panic & stop goroutines")
+			}
+
+			copy(ar, is)
+			calls = 0
+			v = catchSorty(func() {
+				SortStable(n, func(i, k, r, s int) bool {
+					if atomic.AddUint64(&calls, 1) == m {
+						panic("stable")
+					}
+					if ar[i] < ar[k] {
+						if r != s {
+							ar[r], ar[s] = ar[s], ar[r]
+						}
+						return true
+					}
+					return false
+				})
+			})
+			if v != "stable" || !settled(ngr) {
+				t.Fatal("SortStable must propagate panic & stop goroutines")
+			}
+		}
+
+		// panics during selection are in the caller, they keep their value
+		for _, srt := range [...]func(Lesswap){
+			func(lsw Lesswap) { SelectLsw(n, n/2, lsw) },
+			func(lsw Lesswap) { SortPartialLsw(n, n/2, lsw) }} {
+
+			ar := slices.Clone(is)
+			var calls uint64
+			v := func() (v any) {
+				defer func() { v = recover() }()
+				srt(func(i, k, r, s int) bool {
+					if atomic.AddUint64(&calls, 1) == n/2 {
+						panic("select")
+					}
+					if ar[i] < ar[k] {
+						if r != s {
+							ar[r], ar[s] = ar[s], ar[r]
+						}
+						return true
+					}
+					return false
+				})
+				return
+			}()
+			if v != "select" || !settled(ngr) {
+				t.Fatal("Lsw selection must propagate panic as is:", v)
+			}
+		}
+	}
+}
+
+// test Pool & Sorter.Pool, also from concurrent goroutines
+// compare each result with standard slices.Sort
+func TestPool(t *testing.T) {
+	tsPtr = t
+	const n = 1 << 18
+	fillSrc()
+
+	wp := NewPool(maxMaxGor)
+	defer wp.Close()
+	so := NewSorter()
+	so.Pool, so.Descending = wp, true
+
+	ch := make(chan bool)
+	for g := 0; g < 4; g++ {
+		go func(g int) {
+			ok := true
+			for _, m := range [...]int{2*(MaxLenRec+1) + g, n/3 + g, n - g} {
+				ar := slices.Clone(srcBuf[g*m/4:][:m])
+				rf := slices.Clone(ar)
+				slices.Sort(rf)
+				wp.SortSlice(ar)
+				ok = ok && slices.Equal(ar, rf)
+
+				ss := make([]string, m)
+				for i, u := range ar {
+					ss[i] = string(make([]byte, u%100))
+				}
+				wp.SortLen(ss)
+				ok = ok && IsSortedLen(ss) == 0
+				so.SortLen(ss)
+				ok = ok && IsSortedLenDesc(ss) == 0
+
+				// stable, radix & counting sorts, argsort in descending order
+				bb := make([][]byte, m)
+				for i, s := range ss {
+					bb[i] = []byte(s)
+				}
+				so.SortSliceStable(bb, nil)
+				ok = ok && IsSortedSliceDesc(bb) == 0
+
+				bs := sixb.Slice[byte](slices.Clone(ar))
+				so.SortSlice(bs)
+				ok = ok && IsSortedSliceDesc(bs) == 0
+
+				copy(ar, srcBuf[g*m/4:])
+				ApplyPerm(ar, so.ArgsortStable(ar))
+				ok = ok && IsSortedSliceDesc(ar) == 0
+
+				copy(ar, srcBuf[g*m/4:])
+				so.SortSliceRadix(ar, nil)
+				slices.Reverse(ar)
+				ok = ok && slices.Equal(ar, rf)
+
+				copy(ar, srcBuf[g*m/4:])
+				wp.Sort(m, func(i, k, r, s int) bool {
+					if ar[i] < ar[k] {
+						if r != s {
+							ar[r], ar[s] = ar[s], ar[r]
+						}
+						return true
+					}
+					return false
+				})
+				ok = ok && slices.Equal(ar, rf)
+			}
+			ch <- ok
+		}(g)
+	}
+	for g := 0; g < 4; g++ {
+		if !<-ch {
+			t.Fatal("Pool does not work")
+		}
+	}
+
+	ar, rf := slices.Clone(srcBuf[:n]), slices.Clone(srcBuf[:n])
+	slices.Sort(rf)
+	lsw := func(i, k, r, s int) bool {
+		if ar[i] < ar[k] {
+			if r != s {
+				ar[r], ar[s] = ar[s], ar[r]
+			}
+			return true
+		}
+		return false
+	}
+	if a := testing.AllocsPerRun(10, func() {
+		copy(ar, srcBuf[:n])
+		wp.Sort(n, lsw)
+	}); a != 0 || !slices.Equal(ar, rf) {
+		t.Fatal("Pool.Sort must not allocate:", a)
+	}
+
+	// counting sort of a medium-size slice
+	bs := make([]byte, 3000)
+	var pb any = bs
+	if a := testing.AllocsPerRun(10, func() {
+		copy(bs, sixb.Slice[byte](srcBuf))
+		wp.SortSlice(pb)
+	}); a != 0 || IsSortedSlice(bs) != 0 {
+		t.Fatal("Pool.SortSlice must not allocate for counting sort:", a)
+	}
+
+	// Close overlapping a sort
+	wq := NewPool(maxMaxGor)
+	copy(ar, srcBuf[:n])
+	go wq.Close()
+	wq.Sort(n, lsw)
+	wq.SortSlice(ar)
+	if !slices.Equal(ar, rf) {
+		t.Fatal("Pool must work during & after Close")
+	}
+}
+
+// test SetGlobalMaxGor() with concurrent calls
+// compare each result with standard slices.Sort
+func TestGlobalMaxGor(t *testing.T) {
+	tsPtr = t
+	const n, calls, budget = 1 << 18, 4, 2
+	fillSrc()
+
+	SetGlobalMaxGor(budget)
+	defer SetGlobalMaxGor(0)
+	MaxGor = maxMaxGor
+
+	var most uint64 // max observed number of spawned goroutines
+	ch := make(chan bool)
+	for g := 0; g < calls; g++ {
+		go func(g int) {
+			ar := slices.Clone(srcBuf[g*n/4:][:n])
+			rf := slices.Clone(ar)
+			slices.Sort(rf)
+			lsw := func(i, k, r, s int) bool {
+				if m := atomic.LoadUint64(&globalGor); m > atomic.LoadUint64(&most) {
+					atomic.StoreUint64(&most, m) // racy but good enough
+				}
+				if ar[i] < ar[k] {
+					if r != s {
+						ar[r], ar[s] = ar[s], ar[r]
+					}
+					return true
+				}
+				return false
+			}
+			Sort(n, lsw)
+			ok := slices.Equal(ar, rf)
+
+			copy(ar, srcBuf[g*n/4:])
+			SortStable(n, lsw) // reserves goroutines per merge round
+			ok = ok && slices.Equal(ar, rf)
+
+			copy(ar, srcBuf[g*n/4:])
+			SortPartial(ar, n/2)
+			ok = ok && slices.Equal(ar[:n/2], rf[:n/2])
+
+			copy(ar, srcBuf[g*n/4:])
+			SelectMany(ar, []int{n / 4, n / 2, 3 * n / 4})
+			ok = ok && ar[n/4] == rf[n/4] && ar[n/2] == rf[n/2] && ar[3*n/4] == rf[3*n/4]
+
+			copy(ar, srcBuf[g*n/4:])
+			SortSlice(ar)
+			ch <- ok && slices.Equal(ar, rf)
+		}(g)
+	}
+	for g := 0; g < calls; g++ {
+		if !<-ch {
+			t.Fatal("sorting with global budget does not work")
+		}
+	}
+	if most > budget || atomic.LoadUint64(&globalGor) != 0 {
+		t.Fatal("global budget is not respected", most)
+	}
+
+	// calls without a budget are not counted
+	SetGlobalMaxGor(0)
+	var counted atomic.Bool
+	ar := slices.Clone(srcBuf[:n])
+	Sort(n, func(i, k, r, s int) bool {
+		if atomic.LoadUint64(&globalGor) != 0 {
+			counted.Store(true)
+		}
+		if ar[i] < ar[k] {
+			if r != s {
+				ar[r], ar[s] = ar[s], ar[r]
+			}
+			return true
+		}
+		return false
+	})
+	if counted.Load() || IsSortedSlice(ar) != 0 {
+		t.Fatal("calls without a budget must not be counted")
+	}
+}
+
+// counts submitted work
+type countExec uint64
+
+func (ce *countExec) Go(fn func()) {
+	atomic.AddUint64((*uint64)(ce), 1)
+	go fn()
+}
+
+// test SetExecutor() for all kinds of concurrent work
+// compare each result with standard slices.Sort
+func TestExecutor(t *testing.T) {
+	tsPtr = t
+	const n = 1 << 19
+	fillSrc()
+
+	var ce countExec
+	SetExecutor(&ce)
+	defer SetExecutor(nil)
+	MaxGor = maxMaxGor
+
+	is := slices.Clone(srcBuf[:n])
+	ri := slices.Clone(is)
+	slices.Sort(ri)
+	fs := stableFloats(n)
+
+	for _, srt := range [...]func([]uint32){
+		func(ar []uint32) { SortSlice(ar) },
+		func(ar []uint32) { SortFunc(ar, cmp.Compare[uint32]) },
+		func(ar []uint32) { SortPairs(ar, make([]int, n)) },
+		func(ar []uint32) { Sort(n, lswU4(ar)) },
+		func(ar []uint32) { SortStable(n, lswU4(ar)) },
+	} {
+		ar := slices.Clone(is)
+		ce = 0
+		srt(ar)
+		if ce == 0 || !slices.Equal(ar, ri) {
+			t.Fatal("sorting via Executor does not work")
+		}
+	}
+
+	ce = 0
+	SortSliceStable(fs, nil)
+	if ce == 0 || IsSortedSlice(fs) != 0 {
+		t.Fatal("SortSliceStable via Executor does not work")
+	}
+
+	ce = 0 // ranges within k are sorted concurrently
+	ar := slices.Clone(is)
+	SortPartial(ar, n/2)
+	if ce == 0 || !slices.Equal(ar[:n/2], ri[:n/2]) {
+		t.Fatal("SortPartial via Executor does not work")
+	}
+
+	wp := NewPool(maxMaxGor)
+	defer wp.Close()
+	SetExecutor(wp)
+	ar = slices.Clone(is)
+	SortSlice(ar)
+	if !slices.Equal(ar, ri) {
+		t.Fatal("sorting via Pool as Executor does not work")
+	}
+}
+
+// test heapsort fallback of kernels that run out of partitioning depth
+func TestDepth(t *testing.T) {
+	tsPtr = t
+	const n = 1 << 16
+	fillSrc()
+
+	is := slices.Clone(srcBuf[:n])
+	ss := make([]string, n)
+	for i, v := range is {
+		ss[i] = fmt.Sprint(v % 1e5)
+	}
+
+	for dep := 0; dep < 4; dep++ {
+		lm := limits{MaxLenIns, MaxLenRec, dep}
+
+		for _, srt := range [...]func([]uint32) []uint32{
+			func(ar []uint32) []uint32 { longI(ar, lm, nil); return ar },
+			func(ar []uint32) []uint32 { longC(ar, lm, nil, cmp.Compare[uint32]); return ar },
+			func(ar []uint32) []uint32 { longP(ar, make([]int, n), lm, nil); return ar },
+			func(ar []uint32) []uint32 { long(lswU4(ar), 0, n-1, lm, nil); return ar },
+			func(ar []uint32) []uint32 { ar = ar[:lm.rec]; shortI(ar, lm); return ar },
+			func(ar []uint32) []uint32 {
+				ar = ar[:lm.rec]
+				short(lswU4(ar), 0, lm.rec-1, lm)
+				return ar
+			},
+		} {
+			ar := srt(slices.Clone(is))
+			ri := slices.Clone(is[:len(ar)])
+			slices.Sort(ri)
+			if !slices.Equal(ar, ri) {
+				t.Fatal("heapsort fallback does not work")
+			}
+		}
+
+		br := make([][]byte, n)
+		for i, s := range ss {
+			br[i] = []byte(s)
+		}
+		longB(br, lm, nil)
+		if !slices.IsSortedFunc(br, bytes.Compare) {
+			t.Fatal("heapsort fallback does not work for [][]byte")
+		}
+
+		sr := slices.Clone(ss)
+		longHL(sr, lm, nil)
+		if !slices.IsSortedFunc(sr, cmpLen[string]) {
+			t.Fatal("heapsort fallback does not work by length")
+		}
+	}
+}
+
+// test sorting presorted & nearly sorted inputs, which must take near-linear time
+func TestPresorted(t *testing.T) {
+	tsPtr = t
+	const n = 1 << 16
+	fillSrc()
+
+	ri := slices.Clone(srcBuf[:n])
+	slices.Sort(ri)
+	rr := slices.Clone(ri)
+	slices.Reverse(rr)
+	ns := slices.Clone(ri) // nearly sorted
+	for i := 1; i < n; i += n / 4 {
+		ns[i-1], ns[i] = ns[i], ns[i-1]
+	}
+
+	// only partitions without swaps try partInsertion*()
+	ar := slices.Clone(rr)
+	if _, sw := partOneO(ri, ri[n/2]); sw {
+		t.Fatal("partitioning sorted input must not swap")
+	}
+	if _, sw := partOneO(ar, ri[n/2]); !sw {
+		t.Fatal("partitioning reversed input must swap")
+	}
+	if _, sw := partOne(lswU4(ri), 0, n/2, n-1); sw {
+		t.Fatal("partitioning sorted input must not swap")
+	}
+	copy(ar, rr)
+	if _, sw := partOne(lswU4(ar), 0, n/2, n-1); !sw {
+		t.Fatal("partitioning reversed input must swap")
+	}
+
+	for MaxGor = 1; MaxGor <= maxMaxGor; MaxGor++ {
+		for _, in := range [...][]uint32{ri, rr, ns} {
+			var cnt atomic.Int64
+			for _, srt := range [...]func([]uint32){
+				func(ar []uint32) { SortSlice(ar) },
+				func(ar []uint32) { SortPairs(ar, make([]int, n)) },
+				func(ar []uint32) {
+					SortFunc(ar, func(a, b uint32) int {
+						cnt.Add(1)
+						return cmp.Compare(a, b)
+					})
+				},
+				func(ar []uint32) {
+					lsw := lswU4(ar)
+					Sort(n, func(i, k, r, s int) bool {
+						cnt.Add(1)
+						return lsw(i, k, r, s)
+					})
+				},
+			} {
+				ar := slices.Clone(in)
+				srt(ar)
+				if !slices.Equal(ar, ri) {
+					t.Fatal("sorting presorted input does not work")
+				}
+			}
+			if cnt.Load() > 8*n {
+				t.Fatal("sorting presorted input is too slow")
+			}
+
+			ss := make([]string, n)
+			for i, v := range in {
+				ss[i] = strings.Repeat("x", int(v>>26))
+			}
+			SortLen(ss)
+			if IsSortedLen(ss) != 0 {
+				t.Fatal("sorting presorted input by length does not work")
+			}
+		}
+	}
+}
+
+// test sorting inputs with few distinct values
+func TestDuplicates(t *testing.T) {
+	tsPtr = t
+	const n = 1 << 16
+	fillSrc()
+
+	for _, dv := range [...]uint32{2, 5, 17} {
+		is := slices.Clone(srcBuf[:n])
+		for i := range is {
+			is[i] %= dv
+		}
+		ri := slices.Clone(is)
+		slices.Sort(ri)
+
+		for MaxGor = 1; MaxGor <= maxMaxGor; MaxGor++ {
+			var cnt atomic.Int64
+			ar := slices.Clone(is)
+			lsw := lswU4(ar)
+			Sort(n, func(i, k, r, s int) bool {
+				cnt.Add(1)
+				return lsw(i, k, r, s)
+			})
+			if !slices.Equal(ar, ri) || cnt.Load() > 12*n {
+				t.Fatal("Sort with duplicates does not work")
+			}
+
+			ar = slices.Clone(is)
+			fr := make([]float64, n)
+			ss := make([]string, n)
+			bs := make([][]byte, n)
+			ls := make([]string, n)
+			for i, v := range ar {
+				fr[i] = float64(v)
+				ss[i] = fmt.Sprint(v)
+				bs[i] = []byte(ss[i])
+				ls[i] = strings.Repeat("x", int(v))
+			}
+			SortSlice(ar)
+			SortSlice(fr)
+			SortSlice(ss)
+			SortSlice(bs)
+			SortLen(ls)
+			if !slices.Equal(ar, ri) || IsSortedSlice(fr) != 0 || IsSortedSlice(ss) != 0 ||
+				IsSortedSlice(bs) != 0 || IsSortedLen(ls) != 0 {
+				t.Fatal("sorting with duplicates does not work")
+			}
+		}
+	}
+}
+
+func checkRadix[T cmp.Ordered](ar []T, buf any) {
+	ri := slices.Clone(ar)
+	slices.SortFunc(ri, cmpNaN[T])
+	SortSliceRadix(ar, buf)
+	if !slices.EqualFunc(ar, ri, func(a, b T) bool { return a == b || a != a && b != b }) {
+		tsPtr.Fatal("SortSliceRadix does not work")
+	}
+}
+
+// compare SortSliceRadix result with standard slices.Sort
+func TestRadix(t *testing.T) {
+	tsPtr = t
+	const n = 1 << 18
+	fillSrc()
+
+	for MaxGor = 1; MaxGor <= maxMaxGor; MaxGor++ {
+		for _, k := range [...]int{MinLenRadix - 1, MinLenRadix, 3 * MinLenRadix, n} {
+			u4 := srcBuf[:k]
+			u8 := sixb.Slice[uint64](srcBuf[:2*k])
+			checkRadix(slices.Clone(u4), nil)
+			checkRadix(slices.Clone(u8), make([]uint64, k))
+			checkRadix(sixb.Slice[int32](slices.Clone(u4)), make([]int32, k-1)) // short buf
+			checkRadix(sixb.Slice[int64](slices.Clone(u8)), nil)
+
+			i8 := make([]int, k) // small range, most passes skipped
+			for i := range i8 {
+				i8[i] = int(srcBuf[i]%1000) - 500
+			}
+			checkRadix(i8, make([]int, k))
+
+			for _, NaNoption = range [...]FloatOption{NaNsmall, NaNlarge} {
+				f4 := sixb.Slice[float32](slices.Clone(u4)) // includes NaNs, ±0, ±Inf
+				f8 := sixb.Slice[float64](slices.Clone(u8))
+				f4[0], f4[k-1] = float32(math.Inf(-1)), float32(math.Copysign(0, -1))
+				f8[0], f8[k-1] = math.NaN(), math.Inf(1)
+				checkRadix(f4, make([]float32, k))
+				checkRadix(f8, nil)
+			}
+		}
+	}
+
+	so := NewSorter()
+	so.Descending = true
+	ar := slices.Clone(srcBuf[:n])
+	so.SortSliceRadix(ar, nil)
+	fr := sixb.Slice[float32](slices.Clone(srcBuf[:n]))
+	so.SortSliceRadix(fr, nil)
+	if IsSortedSliceDesc(ar) != 0 || IsSortedSliceDesc(fr) != 0 {
+		t.Fatal("Sorter.SortSliceRadix does not work")
+	}
+
+	// pointers are not radix sorted, buf of another type is not used
+	ps := make([]*uint32, n)
+	for i := range ps {
+		ps[i] = &ar[i]
+	}
+	bu, bp := make([]uint64, n), make([]*uint32, n)
+	SortSliceRadix(ps, bu)
+	u8 := sixb.Slice[uint64](slices.Clone(srcBuf[:2*n]))
+	SortSliceRadix(u8, bp)
+	SortSliceRadix(sixb.Slice[uintptr](slices.Clone(srcBuf[:2*n])), bu)
+	if IsSortedSlice(ps) != 0 || IsSortedSlice(u8) != 0 ||
+		slices.ContainsFunc(bu, func(u uint64) bool { return u != 0 }) ||
+		slices.ContainsFunc(bp, func(p *uint32) bool { return p != nil }) {
+		t.Fatal("SortSliceRadix must not use buf of another type")
+	}
+	MaxGor = 1
+	var pa any = ps
+	if testing.AllocsPerRun(5, func() { SortSliceRadix(pa, nil) }) != 0 {
+		t.Fatal("SortSliceRadix must sort pointers in-place")
+	}
+}
+
+// test multikey quicksort with shared prefixes, prefixes of other members & duplicates
+func TestMultikey(t *testing.T) {
+	const n = 1 << 17
+	fillSrc()
+	src := sixb.Slice[byte](srcBuf)
+	prefix := [...]string{"", "https://example.com/", "/usr/local/share/doc/pkg-"}
+
+	for MaxGor = 1; MaxGor <= maxMaxGor; MaxGor++ {
+		for _, k := range [...]int{5, MaxLenInsFC + 1, MaxLenRecFC + 1, n} {
+			ss := make([]string, k)
+			for i := range ss {
+				b := src[3*i : 3*i+3]
+				ss[i] = prefix[b[0]%3] + string(b[1:1+b[1]%3]) + strings.Repeat("x", int(b[2]%4))
+			}
+			bs := make([][]byte, k)
+			for i := range bs {
+				bs[i] = []byte(ss[i])
+			}
+
+			rs := slices.Clone(ss)
+			slices.Sort(rs)
+			SortSliceRadix(ss, nil)
+			if !slices.Equal(ss, rs) {
+				t.Fatal("SortSliceRadix does not work for strings")
+			}
+			SortSliceRadix(bs, nil)
+			if !slices.EqualFunc(bs, rs, func(a []byte, b string) bool { return string(a) == b }) {
+				t.Fatal("SortSliceRadix does not work for []byte")
+			}
+		}
+	}
+
+	so := NewSorter()
+	so.Descending = true
+	ss := make([]string, n)
+	for i := range ss {
+		ss[i] = "key/" + fmt.Sprint(srcBuf[i]%1000)
+	}
+	so.SortSliceRadix(ss, nil)
+	if IsSortedSliceDesc(ss) != 0 {
+		t.Fatal("Sorter.SortSliceRadix does not work for strings")
+	}
+	MaxGor = 1 // conversions in comparisons should not allocate
+	bs := make([][]byte, n)
+	for i := range bs {
+		bs[i] = []byte(ss[i])
+	}
+	if n := testing.AllocsPerRun(2, func() {
+		SortSliceRadix(ss, nil)
+		SortSliceRadix(bs, nil)
+	}); n > 2 {
+		t.Fatal("multikey quicksort allocates", n)
+	}
+	MaxGor = 3
+}
+
+// test prefix keys mode with short members, zero bytes & long common prefixes
+func TestPrefixKeys(t *testing.T) {
+	const n = 1 << 16
+	fillSrc()
+	src := sixb.Slice[byte](srcBuf)
+	so := NewSorter()
+	so.PrefixKeys = true
+
+	for _, so.Descending = range [...]bool{false, true} {
+		for _, so.MaxGor = range [...]uint64{1, 3} {
+			ss := make([]string, n)
+			for i := range ss {
+				b := src[2*i : 2*i+2]
+				ss[i] = "dir/file" + string(b[:b[0]%3])
+				if b[1]&1 != 0 {
+					ss[i] = ss[i][b[1]%9:] + "\x00"
+				}
+			}
+			bs := make([][]byte, n)
+			for i := range bs {
+				bs[i] = []byte(ss[i])
+			}
+
+			rs := slices.Clone(ss)
+			slices.Sort(rs)
+			if so.Descending {
+				slices.Reverse(rs)
+			}
+			so.SortSlice(ss)
+			so.SortSlice(bs)
+			if !slices.Equal(ss, rs) || !slices.EqualFunc(bs, rs,
+				func(a []byte, b string) bool { return string(a) == b }) {
+				t.Fatal("Sorter.SortSlice does not work with PrefixKeys")
+			}
+		}
+	}
+}
+
+// sort ar with SortSlice & SortSliceDesc, compare with slices.Sort
+func checkCounting[T cmp.Ordered](ar []T) {
+	ri := slices.Clone(ar)
+	slices.Sort(ri)
+	ad := slices.Clone(ar)
+	SortSlice(ar)
+	SortSliceDesc(ad)
+	slices.Reverse(ad)
+	if !slices.Equal(ar, ri) || !slices.Equal(ad, ri) || IsSortedSlice(ar) != 0 {
+		tsPtr.Fatalf("SortSlice does not work for %T", ar)
+	}
+}
+
+// test small integer types, []bool and wider integers with a small range of values
+func TestCounting(t *testing.T) {
+	tsPtr = t
+	const n = 1 << 18
+	fillSrc()
+	src := sixb.Slice[byte](srcBuf)
+
+	for MaxGor = 1; MaxGor <= maxMaxGor; MaxGor++ {
+		for _, k := range [...]int{MaxLenRec, MaxLenRec + 1, 3000, n} {
+			checkCounting(sixb.Slice[int8](slices.Clone(src[:k])))
+			checkCounting(slices.Clone(src[:k]))
+			checkCounting(sixb.Slice[int16](slices.Clone(src[:2*k])))
+			checkCounting(sixb.Slice[uint16](slices.Clone(src[:2*k])))
+
+			bs, rb := make([]bool, k), make([]bool, k)
+			f := k // falses first
+			for i := range bs {
+				if bs[i] = src[i]&1 != 0; bs[i] {
+					f--
+					rb[f] = true
+				}
+			}
+			SortSlice(bs)
+			if !slices.Equal(bs, rb) || IsSortedSlice(bs) != 0 {
+				t.Fatal("SortSlice does not work for []bool")
+			}
+
+			u4 := make([]uint32, k) // small ranges at the ends of value spaces
+			i8 := make([]int64, k)
+			i4 := make([]int, k)
+			for i := range u4 {
+				v := int(srcBuf[i] % uint32(k/MinLenCount))
+				u4[i] = math.MaxUint32 - uint32(v)
+				i8[i] = math.MinInt64 + int64(v)
+				i4[i] = v - k/4
+			}
+			checkCounting(u4)
+			checkCounting(i8)
+			checkCounting(i4)
+		}
+	}
+}
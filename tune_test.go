@@ -10,10 +10,12 @@ package sorty
 
 import (
 	"fmt"
+	"math"
 	"testing"
 	"time"
 
 	"github.com/jfcg/opt"
+	sb "github.com/jfcg/sixb/v2"
 )
 
 func printSec(_ string, d time.Duration) float64 {
@@ -35,7 +37,7 @@ var (
 		func() float64 { return sumDurLswU4(false) + sumDurLswF4(false) },
 
 		// optimize for string
-		func() float64 { return sumDurS(false) },
+		func() float64 { return sumDurS(false, false) },
 
 		// optimize for lesswap sort (string key)
 		func() float64 { return sumDurLswS(false) }}
@@ -69,3 +71,91 @@ func TestOptimize(t *testing.T) {
 		optRun("FC", 40, 300)
 	}
 }
+
+// return total duration of sorting random uint32 & uint64 slices of length n that
+// fill aaBuf via srt(), for 1..maxMaxGor goroutines
+func sumDurRadix(n int, srt func(ar, buf any)) (sum time.Duration) {
+	for MaxGor = 1; MaxGor <= maxMaxGor; MaxGor++ {
+		fillSrc()
+		copy(aaBuf, srcBuf)
+		now := time.Now()
+		for i := n; i <= len(aaBuf); i += n {
+			srt(aaBuf[i-n:i], bbBuf)
+		}
+		sum += time.Since(now)
+
+		a8, b8 := sb.Slice[uint64](aaBuf), sb.Slice[uint64](bbBuf)
+		copy(aaBuf, srcBuf)
+		now = time.Now()
+		for i := n; i <= len(a8); i += n {
+			srt(a8[i-n:i], b8)
+		}
+		sum += time.Since(now)
+	}
+	return
+}
+
+// Find min integer slice length for radix sort, where it gets faster than QuickSort
+// Takes a long time, run with -tags tuneparam
+func TestOptimizeRadix(t *testing.T) {
+	tsPtr = t
+	fmt.Println("\nMinLenRadix:")
+	quick := func(ar, _ any) { SortSlice(ar) }
+	MinLenRadix = 0 // always radix sort
+
+	lo, hi := 1<<6, 1<<16
+	for hi-lo > lo>>3 {
+		n := int(math.Sqrt(float64(lo * hi)))
+		dr, dq := sumDurRadix(n, SortSliceRadix), sumDurRadix(n, quick)
+		fmt.Printf("%5d %5.2fs %5.2fs\n", n, dr.Seconds(), dq.Seconds())
+		if dr < dq {
+			hi = n
+		} else {
+			lo = n
+		}
+	}
+	fmt.Println(hi)
+}
+
+// return total duration of sorting uint32 slices of lengths 2^11, 2^15, 2^19 & 2^23
+// that fill aaBuf and have ranges of values length/r, for 1..maxMaxGor goroutines
+func sumDurCount(r int) (sum time.Duration) {
+	for MaxGor = 1; MaxGor <= maxMaxGor; MaxGor++ {
+		fillSrc()
+		for n := 1 << 11; n <= 1<<23; n <<= 4 {
+			for i, v := range srcBuf {
+				aaBuf[i] = v % uint32(n/r)
+			}
+			now := time.Now()
+			for i := n; i <= len(aaBuf); i += n {
+				SortSlice(aaBuf[i-n : i])
+			}
+			sum += time.Since(now)
+		}
+	}
+	return
+}
+
+// Find min ratio of integer slice length to its range of values for counting sort,
+// where it gets faster than QuickSort. Takes a long time, run with -tags tuneparam
+func TestOptimizeCount(t *testing.T) {
+	tsPtr = t
+	fmt.Println("\nMinLenCount:")
+
+	lo, hi := 1, 1<<8
+	for hi-lo > 1 {
+		r := int(math.Sqrt(float64(lo * hi)))
+		MinLenCount = 1 // always counting sort
+		dc := sumDurCount(r)
+		MinLenCount = math.MaxInt // never counting sort
+		dq := sumDurCount(r)
+		fmt.Printf("%3d %5.2fs %5.2fs\n", r, dc.Seconds(), dq.Seconds())
+		if dc < dq {
+			hi = r
+		} else {
+			lo = r
+		}
+	}
+	MinLenCount = hi
+	fmt.Println(hi)
+}